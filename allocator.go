@@ -0,0 +1,110 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Allocator recycles the internal nodes of queues, allowing node memory to be
+// shared across queue instances. Queues created with the WithAllocator option
+// take their internal nodes from the allocator as they grow and return them
+// when released by Init, Shrink or the trim policy.
+//
+// Allocator is backed by a sync.Pool, so it's safe for concurrent use by
+// queues in different goroutines, and pooled nodes not reused for a while are
+// eventually released by the garbage collector.
+type Allocator struct {
+	// size holds the size of the node slices managed by the allocator.
+	size int
+
+	// pool holds the released nodes.
+	pool sync.Pool
+
+	// hits holds the number of nodes taken from the pool.
+	hits atomic.Uint64
+
+	// misses holds the number of nodes allocated because the pool was empty.
+	misses atomic.Uint64
+
+	// puts holds the number of nodes returned to the pool.
+	puts atomic.Uint64
+}
+
+// AllocatorStats holds the usage statistics of an allocator.
+type AllocatorStats struct {
+	// Hits holds the number of nodes reused from the pool.
+	Hits uint64
+
+	// Misses holds the number of nodes allocated because the pool was empty.
+	Misses uint64
+
+	// Puts holds the number of nodes returned to the pool.
+	Puts uint64
+}
+
+// HitRate returns the ratio of requested nodes that were reused from the pool,
+// or 0 if no nodes were requested yet.
+func (s AllocatorStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewAllocator returns an allocator of nodes holding size values each.
+// To be used by a queue, size must match the queue max internal slice size,
+// which by default is 256.
+// NewAllocator panics if size is less than 1.
+func NewAllocator(size int) *Allocator {
+	if size < 1 {
+		panic("queue: allocator slice size must be greater than zero")
+	}
+	return &Allocator{size: size}
+}
+
+// Stats returns the current usage statistics of allocator a.
+func (a *Allocator) Stats() AllocatorStats {
+	return AllocatorStats{
+		Hits:   a.hits.Load(),
+		Misses: a.misses.Load(),
+		Puts:   a.puts.Load(),
+	}
+}
+
+// get returns an empty, unlinked node, reusing a pooled one if available.
+func (a *Allocator) get() *node {
+	if n, ok := a.pool.Get().(*node); ok {
+		a.hits.Add(1)
+		return n
+	}
+	a.misses.Add(1)
+	return &node{v: make([]interface{}, a.size)}
+}
+
+// put returns node n, which must hold no values, to the pool.
+func (a *Allocator) put(n *node) {
+	n.n = nil
+	n.p = nil
+	a.puts.Add(1)
+	a.pool.Put(n)
+}
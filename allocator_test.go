@@ -0,0 +1,138 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestNewAllocatorWithInvalidSizeShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected: panic; Got: none")
+		}
+	}()
+	queue.NewAllocator(0)
+}
+
+func TestNewWithOptionsWithMismatchedAllocatorShouldReturnError(t *testing.T) {
+	a := queue.NewAllocator(128)
+	if _, err := queue.NewWithOptions(queue.WithAllocator(a)); err == nil {
+		t.Error("Expected: error; Got: nil")
+	}
+	if _, err := queue.NewWithOptions(queue.WithAllocator(a), queue.WithMaxInternalSliceSize(128)); err != nil {
+		t.Errorf("Expected: nil error; Got: %v", err)
+	}
+}
+
+func TestAllocatorStatsWhenUnusedShouldReturnZero(t *testing.T) {
+	a := queue.NewAllocator(256)
+	s := a.Stats()
+	if s.Hits != 0 || s.Misses != 0 || s.Puts != 0 {
+		t.Errorf("Expected: zero stats; Got: %+v", s)
+	}
+	if r := s.HitRate(); r != 0 {
+		t.Errorf("Expected: 0; Got: %v", r)
+	}
+}
+
+func TestAllocatorWithNonDefaultSizesShouldGetNodesBack(t *testing.T) {
+	for _, size := range []int{1, 7, 16} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			a := queue.NewAllocator(size)
+			q, err := queue.NewWithOptions(queue.WithAllocator(a), queue.WithMaxInternalSliceSize(size))
+			if err != nil {
+				t.Fatalf("Expected: nil error; Got: %v", err)
+			}
+			for j := 0; j < pushCount; j++ {
+				q.Push(j)
+			}
+			q.Init()
+
+			// All nodes taken from the allocator go back to it, as well as the
+			// first slice, which grows up to the internal slices size.
+			s := a.Stats()
+			if s.Puts == 0 || s.Puts != s.Hits+s.Misses+1 {
+				t.Errorf("Expected: %d nodes returned; Got: %+v", s.Hits+s.Misses+1, s)
+			}
+		})
+	}
+}
+
+func TestAllocatorShouldRecycleNodesAcrossQueues(t *testing.T) {
+	a := queue.NewAllocator(256)
+
+	for i := 0; i < refillCount; i++ {
+		q, err := queue.NewWithOptions(queue.WithAllocator(a))
+		if err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < pushCount; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		q.Init()
+	}
+
+	// Each queue uses the first slice plus 3 nodes from the allocator and
+	// returns all of them on Init.
+	s, want := a.Stats(), uint64(3*refillCount)
+	if s.Hits+s.Misses != want {
+		t.Errorf("Expected: %d nodes requested; Got: %d", want, s.Hits+s.Misses)
+	}
+	if s.Puts != want {
+		t.Errorf("Expected: %d nodes returned; Got: %d", want, s.Puts)
+	}
+	if s.Hits == 0 || s.HitRate() <= 0 {
+		t.Errorf("Expected: nodes to be reused; Got: %+v", s)
+	}
+}
+
+func TestAllocatorShouldReceiveNodesReleasedByShrinkAndTrim(t *testing.T) {
+	a := queue.NewAllocator(256)
+	q, _ := queue.NewWithOptions(queue.WithAllocator(a))
+	for i := 0; i < pushCount*2; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < pushCount; i++ {
+		q.Pop()
+	}
+	q.Shrink()
+	puts := a.Stats().Puts
+	if puts == 0 {
+		t.Error("Expected: Shrink to return the spare nodes; Got: none")
+	}
+
+	q.SetTrimPolicy(&queue.TrimPolicy{})
+	for q.Len() > 0 {
+		q.Pop()
+	}
+	if a.Stats().Puts == puts {
+		t.Error("Expected: the trim policy to return the spare nodes; Got: none")
+	}
+}
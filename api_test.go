@@ -119,3 +119,197 @@ func TestPopWithNilValuesShouldReturnAllValuesInOrder(t *testing.T) {
 		t.Error("Expected: empty slice (ok=false); Got: ok=true")
 	}
 }
+
+func TestBackWithZeroValueAndEmptyShouldReturnAsEmpty(t *testing.T) {
+	var q queue.Queue
+
+	if _, ok := q.Back(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.PopBack(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestPushFrontWithZeroValueShouldReturnReadyToUsequeue(t *testing.T) {
+	var q queue.Queue
+	q.PushFront(2)
+	q.PushFront(1)
+
+	v, ok := q.Front()
+	if !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	v, ok = q.Back()
+	if !ok || v.(int) != 2 {
+		t.Errorf("Expected: 2; Got: %d", v)
+	}
+	v, ok = q.PopBack()
+	if !ok || v.(int) != 2 {
+		t.Errorf("Expected: 2; Got: %d", v)
+	}
+	v, ok = q.Back()
+	if !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	v, ok = q.PopBack()
+	if !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	if _, ok = q.Back(); ok {
+		t.Error("Expected: empty slice (ok=false); Got: ok=true")
+	}
+	if _, ok = q.PopBack(); ok {
+		t.Error("Expected: empty slice (ok=false); Got: ok=true")
+	}
+}
+
+func TestAtSetSwapShouldAccessElementsByIndex(t *testing.T) {
+	var q queue.Queue
+	q.Push(1)
+	q.Push(2)
+	q.Push(3)
+
+	if v := q.At(0); v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	if v := q.At(2); v.(int) != 3 {
+		t.Errorf("Expected: 3; Got: %d", v)
+	}
+	q.Set(1, 4)
+	if v := q.At(1); v.(int) != 4 {
+		t.Errorf("Expected: 4; Got: %d", v)
+	}
+	q.Swap(0, 2)
+	if v, _ := q.Front(); v.(int) != 3 {
+		t.Errorf("Expected: 3; Got: %d", v)
+	}
+	if v, _ := q.Back(); v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+}
+
+func TestAtSetSwapWithOutOfRangeIndexShouldPanic(t *testing.T) {
+	var q queue.Queue
+	q.Push(1)
+
+	tests := map[string]func(){
+		"At(-1)":      func() { q.At(-1) },
+		"At(Len)":     func() { q.At(1) },
+		"Set(Len)":    func() { q.Set(1, 1) },
+		"Swap(0,Len)": func() { q.Swap(0, 1) },
+		"Swap(Len,0)": func() { q.Swap(1, 0) },
+	}
+	for name, f := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("Expected: panic; Got: none")
+				}
+			}()
+			f()
+		})
+	}
+}
+
+func TestPopNAppendToWithZeroValueAndEmptyShouldReturnAsEmpty(t *testing.T) {
+	var q queue.Queue
+
+	if vs := q.PopN(10); vs != nil {
+		t.Errorf("Expected: nil; Got: %v", vs)
+	}
+	if vs := q.AppendTo(nil, 10); vs != nil {
+		t.Errorf("Expected: nil; Got: %v", vs)
+	}
+	q.PushSlice(nil)
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestPopNShouldReturnAtMostLenElements(t *testing.T) {
+	var q queue.Queue
+	q.PushSlice([]interface{}{1, 2, 3})
+
+	vs := q.PopN(2)
+	if len(vs) != 2 || vs[0].(int) != 1 || vs[1].(int) != 2 {
+		t.Errorf("Expected: [1 2]; Got: %v", vs)
+	}
+	vs = q.PopN(2)
+	if len(vs) != 1 || vs[0].(int) != 3 {
+		t.Errorf("Expected: [3]; Got: %v", vs)
+	}
+	if vs := q.PopN(-1); vs != nil {
+		t.Errorf("Expected: nil; Got: %v", vs)
+	}
+}
+
+func TestAppendToShouldAppendToTheGivenSlice(t *testing.T) {
+	var q queue.Queue
+	q.PushSlice([]interface{}{2, 3})
+
+	vs := q.AppendTo([]interface{}{1}, 10)
+	if len(vs) != 3 || vs[0].(int) != 1 || vs[1].(int) != 2 || vs[2].(int) != 3 {
+		t.Errorf("Expected: [1 2 3]; Got: %v", vs)
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestResetReserveWithZeroValueShouldReturnAsEmpty(t *testing.T) {
+	var q queue.Queue
+	q.Reset()
+	q.Reserve(0)
+	q.Reserve(-1)
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+	q.Reserve(10)
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestNewWithOptionsWithInvalidSizingShouldReturnError(t *testing.T) {
+	tests := map[string][]queue.Option{
+		"zero first slice size":            {queue.WithFirstSliceSize(0)},
+		"growth factor of one":             {queue.WithSliceGrowthFactor(1)},
+		"max first less than first size":   {queue.WithFirstSliceSize(8), queue.WithMaxFirstSliceSize(4)},
+		"zero max internal slice size":     {queue.WithMaxInternalSliceSize(0)},
+		"negative max internal slice size": {queue.WithMaxInternalSliceSize(-1)},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := queue.NewWithOptions(opts...)
+			if err == nil {
+				t.Error("Expected: error; Got: nil")
+			}
+			if q != nil {
+				t.Errorf("Expected: nil queue; Got: %v", q)
+			}
+		})
+	}
+}
+
+func TestNewWithOptionsShouldReturnReadyToUsequeue(t *testing.T) {
+	q, err := queue.NewWithOptions()
+	if err != nil {
+		t.Fatalf("Expected: nil error; Got: %v", err)
+	}
+	q.Push(1)
+	q.Init()
+	q.Push(2)
+	if v, ok := q.Pop(); !ok || v.(int) != 2 {
+		t.Errorf("Expected: 2; Got: %d", v)
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
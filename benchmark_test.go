@@ -55,8 +55,9 @@ var (
 	tmp  interface{}
 	tmp2 bool
 
-	fillCount   = 10000
-	refillCount = 10
+	fillCount     = 10000
+	refillCount   = 10
+	maxBatchCount = 1000
 )
 
 func BenchmarkMicroservice(b *testing.B) {
@@ -129,6 +130,86 @@ func BenchmarkFill(b *testing.B) {
 	}
 }
 
+func BenchmarkFillWithOptions(b *testing.B) {
+	options := map[string][]queue.Option{
+		// Same sizing as the zero value queue, so any difference to BenchmarkFill
+		// is the cost of the options path.
+		"default": {
+			queue.WithFirstSliceSize(4),
+			queue.WithSliceGrowthFactor(4),
+			queue.WithMaxFirstSliceSize(64),
+			queue.WithMaxInternalSliceSize(256),
+		},
+		"large": {
+			queue.WithMaxInternalSliceSize(4096),
+		},
+	}
+	for name, opts := range options {
+		for _, test := range tests {
+			b.Run(name+"/"+strconv.Itoa(test.count), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					q, _ := queue.NewWithOptions(opts...)
+					for i := 0; i < test.count; i++ {
+						q.Push(nil)
+					}
+					for q.Len() > 0 {
+						tmp, tmp2 = q.Pop()
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFillWithAllocator(b *testing.B) {
+	a := queue.NewAllocator(256)
+	for _, test := range tests {
+		b.Run(strconv.Itoa(test.count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				q, _ := queue.NewWithOptions(queue.WithAllocator(a))
+				for i := 0; i < test.count; i++ {
+					q.Push(nil)
+				}
+				for q.Len() > 0 {
+					tmp, tmp2 = q.Pop()
+				}
+				q.Init()
+			}
+		})
+	}
+}
+
+func BenchmarkFillSlice(b *testing.B) {
+	for _, test := range tests {
+		vs := make([]interface{}, test.count)
+		b.Run(strconv.Itoa(test.count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				q := queue.New()
+				q.PushSlice(vs)
+				for q.Len() > 0 {
+					tmp = q.PopN(maxBatchCount)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFillAppendTo(b *testing.B) {
+	for _, test := range tests {
+		vs := make([]interface{}, test.count)
+		b.Run(strconv.Itoa(test.count), func(b *testing.B) {
+			dst := make([]interface{}, 0, maxBatchCount)
+			for n := 0; n < b.N; n++ {
+				q := queue.New()
+				q.PushSlice(vs)
+				for q.Len() > 0 {
+					dst = q.AppendTo(dst[:0], maxBatchCount)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkRefill(b *testing.B) {
 	for _, test := range tests {
 		b.Run(strconv.Itoa(test.count), func(b *testing.B) {
@@ -147,6 +228,23 @@ func BenchmarkRefill(b *testing.B) {
 	}
 }
 
+func BenchmarkRefillReset(b *testing.B) {
+	for _, test := range tests {
+		b.Run(strconv.Itoa(test.count), func(b *testing.B) {
+			q := queue.New()
+			q.Reserve(test.count)
+			for n := 0; n < b.N; n++ {
+				for n := 0; n < refillCount; n++ {
+					for i := 0; i < test.count; i++ {
+						q.Push(nil)
+					}
+					q.Reset()
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkRefillFull(b *testing.B) {
 	q := queue.New()
 	for i := 0; i < fillCount; i++ {
@@ -0,0 +1,392 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when pushing to or waiting on a closed queue.
+var ErrClosed = errors.New("queue: queue closed")
+
+// BlockingQueue implements a First-In-First-Out (FIFO) queue that is safe for
+// concurrent use, allowing consumers to wait for values to be pushed.
+// BlockingQueue is a Queue protected by a mutex, so it keeps all the Queue
+// performance characteristics.
+// The zero value for BlockingQueue is an empty queue ready to use.
+type BlockingQueue struct {
+	// mu protects all the other fields.
+	mu sync.Mutex
+
+	// q holds the queue values.
+	q Queue
+
+	// waiters holds the consumers waiting for a value, in the order they
+	// started waiting. Each pushed value wakes up a single waiter, while Close
+	// wakes up all of them.
+	waiters Queue
+
+	// cancelled holds the number of cancelled waiters still in waiters.
+	cancelled int
+
+	// ready is signalled when the queue transitions from empty to non-empty.
+	// A nil ready means Ready was never called.
+	ready chan struct{}
+
+	// closed indicates whether Close was called.
+	closed bool
+
+	// clock holds the clock used to time batches.
+	// A nil clock means the system clock is used.
+	clock Clock
+}
+
+// waiter represents a consumer waiting for a value to be pushed.
+type waiter struct {
+	// c receives a value when the waiter is woken up.
+	c chan struct{}
+
+	// woken indicates whether the waiter was woken up.
+	woken bool
+
+	// cancelled indicates whether the waiter stopped waiting before being
+	// woken up.
+	cancelled bool
+}
+
+// NewBlocking returns an initialized blocking queue.
+func NewBlocking() *BlockingQueue {
+	return new(BlockingQueue)
+}
+
+// NewBlockingWithClock returns an initialized blocking queue that uses clock c
+// to time batches.
+func NewBlockingWithClock(c Clock) *BlockingQueue {
+	return &BlockingQueue{clock: c}
+}
+
+// Len returns the number of elements of queue d.
+// The complexity is O(1).
+func (d *BlockingQueue) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.q.Len()
+}
+
+// Push adds value v to the the back of the queue, waking up the consumer
+// that has been waiting for a value for the longest, if any.
+// If the queue is closed, v is not added and ErrClosed is returned.
+// The complexity is O(1).
+func (d *BlockingQueue) Push(v interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	d.q.Push(v)
+	d.wakeOne()
+	if d.q.Len() == 1 {
+		d.signalReady()
+	}
+	return nil
+}
+
+// Ready returns a channel that receives a value when the queue transitions
+// from empty to non-empty, allowing consumers to wait for values in a select
+// statement alongside other channels.
+// The signal is edge-triggered and coalesced: a single value is received no
+// matter how many values are pushed until the queue is empty again, so after
+// receiving from Ready, consumers should call TryPop until it returns false.
+// As the values may have been retrieved by other consumers in the meantime,
+// TryPop may also return false right away.
+// If the queue isn't empty when Ready is first called, the channel is
+// signalled right away.
+// Close closes the channel, so consumers waiting only on Ready learn the queue
+// was closed when they receive a zero value with ok set to false; the values
+// left in the queue can still be retrieved with TryPop.
+func (d *BlockingQueue) Ready() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ready == nil {
+		d.ready = make(chan struct{}, 1)
+		if d.q.Len() > 0 {
+			d.signalReady()
+		}
+		if d.closed {
+			close(d.ready)
+		}
+	}
+	return d.ready
+}
+
+// TryPop retrieves and removes the current element from the front of the queue
+// without waiting.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *BlockingQueue) TryPop() (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.q.Pop()
+}
+
+// PopWait retrieves and removes the current element from the front of the
+// queue, waiting for a value to be pushed if the queue is empty.
+// If ctx is done before a value is available, ctx.Err() is returned.
+// If the queue is closed, the remaining values are still returned and, once
+// the queue is empty, ErrClosed is returned.
+func (d *BlockingQueue) PopWait(ctx context.Context) (interface{}, error) {
+	for {
+		d.mu.Lock()
+		if v, ok := d.q.Pop(); ok {
+			d.mu.Unlock()
+			return v, nil
+		}
+		if d.closed {
+			d.mu.Unlock()
+			return nil, ErrClosed
+		}
+		w := d.addWaiter()
+		d.mu.Unlock()
+
+		select {
+		case <-w.c:
+		case <-ctx.Done():
+			d.cancelWaiter(w)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// PopBatch retrieves and removes up to max elements from the front of the
+// queue. PopBatch waits for the first value to be available, then keeps
+// gathering values until either max values were gathered or maxWait elapsed
+// since the first value was retrieved, whichever comes first.
+// If ctx is done, or the queue is closed and empty, before the first value is
+// available, nil is returned. If ctx is done while gathering values, or the
+// queue is closed, the values gathered so far are returned right away.
+func (d *BlockingQueue) PopBatch(ctx context.Context, max int, maxWait time.Duration) []interface{} {
+	if max <= 0 {
+		return nil
+	}
+	v, err := d.PopWait(ctx)
+	if err != nil {
+		return nil
+	}
+	batch := []interface{}{v}
+
+	var timeout <-chan time.Time
+	if maxWait > 0 {
+		timeout = d.getClock().After(maxWait)
+	}
+	for {
+		d.mu.Lock()
+		batch = d.q.AppendTo(batch, max-len(batch))
+		if len(batch) == max || d.closed || timeout == nil {
+			d.mu.Unlock()
+			return batch
+		}
+		w := d.addWaiter()
+		d.mu.Unlock()
+
+		select {
+		case <-w.c:
+		case <-timeout:
+			// Take whatever arrived up to now, without waiting any longer.
+			d.cancelWaiter(w)
+			timeout = nil
+		case <-ctx.Done():
+			d.cancelWaiter(w)
+			return batch
+		}
+	}
+}
+
+// PopAny retrieves and removes the current element from the front of the first
+// queue in qs that has a value, waiting for a value to be pushed if all the
+// queues are empty. The index in qs of the queue the value was retrieved from
+// is returned alongside the value.
+// When several queues have values, each call tries the queues starting from a
+// randomly chosen one, so a busy queue can't starve the others, no matter how
+// calls with different or overlapping queue sets are interleaved.
+// Closed queues are skipped once empty; if all the queues are closed and
+// empty, ErrClosed is returned. If ctx is done before a value is available,
+// ctx.Err() is returned. In both cases, the returned index is -1.
+func PopAny(ctx context.Context, qs ...*BlockingQueue) (int, interface{}, error) {
+	if len(qs) == 0 {
+		return -1, nil, ErrClosed
+	}
+	cases := make([]reflect.SelectCase, 0, len(qs)+1)
+	ws := make([]*waiter, 0, len(qs))
+	idx := make([]int, 0, len(qs))
+	for {
+		cases = append(cases[:0], reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(ctx.Done()),
+		})
+		ws, idx = ws[:0], idx[:0]
+		start := rand.Intn(len(qs))
+		for i := range qs {
+			j := (start + i) % len(qs)
+			q := qs[j]
+			q.mu.Lock()
+			if v, ok := q.q.Pop(); ok {
+				q.mu.Unlock()
+				for k, w := range ws {
+					qs[idx[k]].cancelWaiter(w)
+				}
+				return j, v, nil
+			}
+			if !q.closed {
+				w := q.addWaiter()
+				ws = append(ws, w)
+				idx = append(idx, j)
+				cases = append(cases, reflect.SelectCase{
+					Dir:  reflect.SelectRecv,
+					Chan: reflect.ValueOf(w.c),
+				})
+			}
+			q.mu.Unlock()
+		}
+		if len(ws) == 0 {
+			return -1, nil, ErrClosed
+		}
+
+		chosen, _, _ := reflect.Select(cases)
+		for k, w := range ws {
+			if k != chosen-1 {
+				qs[idx[k]].cancelWaiter(w)
+			}
+		}
+		if chosen == 0 {
+			return -1, nil, ctx.Err()
+		}
+		// Try the queue that woke us up first, so its value isn't left
+		// behind, with no other consumer woken up for it, while a value from
+		// another queue is retrieved.
+		j := idx[chosen-1]
+		if v, ok := qs[j].TryPop(); ok {
+			return j, v, nil
+		}
+	}
+}
+
+// Close closes queue d, waking up all waiting consumers and closing the
+// channel returned by Ready. After Close, Push returns ErrClosed, while the
+// values already in the queue can still be retrieved. Calling Close more than
+// once has no effect.
+func (d *BlockingQueue) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.closed = true
+	d.wakeAll()
+	if d.ready != nil {
+		close(d.ready)
+	}
+}
+
+// getClock returns the clock used by queue d.
+func (d *BlockingQueue) getClock() Clock {
+	if d.clock == nil {
+		return systemClock{}
+	}
+	return d.clock
+}
+
+// addWaiter registers a new waiter, to be woken up by a later push or close.
+// d.mu must be held.
+func (d *BlockingQueue) addWaiter() *waiter {
+	w := &waiter{c: make(chan struct{}, 1)}
+	d.waiters.Push(w)
+	return w
+}
+
+// cancelWaiter stops waiter w from waiting. If w was already woken up, but
+// didn't receive the wake up, it is passed on to the next waiter, so the value
+// pushed in the meantime doesn't go unnoticed.
+func (d *BlockingQueue) cancelWaiter(w *waiter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if w.woken {
+		select {
+		case <-w.c:
+			d.wakeOne()
+		default:
+		}
+		return
+	}
+	w.cancelled = true
+	d.cancelled++
+	if d.cancelled*2 > d.waiters.Len() {
+		// Most waiters are cancelled, so drop them all at once.
+		for n := d.waiters.Len(); n > 0; n-- {
+			v, _ := d.waiters.Pop()
+			if !v.(*waiter).cancelled {
+				d.waiters.Push(v)
+			}
+		}
+		d.cancelled = 0
+	}
+}
+
+// wakeOne wakes up the waiter that has been waiting for the longest, if any.
+// d.mu must be held.
+func (d *BlockingQueue) wakeOne() {
+	for {
+		v, ok := d.waiters.Pop()
+		if !ok {
+			return
+		}
+		w := v.(*waiter)
+		if w.cancelled {
+			d.cancelled--
+			continue
+		}
+		w.woken = true
+		w.c <- struct{}{}
+		return
+	}
+}
+
+// wakeAll wakes up all waiters.
+// d.mu must be held.
+func (d *BlockingQueue) wakeAll() {
+	for d.waiters.Len() > 0 {
+		d.wakeOne()
+	}
+}
+
+// signalReady signals the ready channel, if any, without blocking.
+// d.mu must be held.
+func (d *BlockingQueue) signalReady() {
+	select {
+	case d.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,545 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestBlockingQueueWithZeroValueShouldReturnReadyToUseQueue(t *testing.T) {
+	var q queue.BlockingQueue
+
+	if _, ok := q.TryPop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if err := q.Push(1); err != nil {
+		t.Errorf("Expected: nil error; Got: %v", err)
+	}
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+	if v, ok := q.TryPop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+}
+
+func TestPopWaitShouldReturnValuePushedLater(t *testing.T) {
+	q := queue.NewBlocking()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Push(1)
+	}()
+	v, err := q.PopWait(context.Background())
+	if err != nil || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v, %v", v, err)
+	}
+}
+
+func TestPopWaitShouldReturnContextErrorWhenCancelled(t *testing.T) {
+	q := queue.NewBlocking()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.PopWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected: %v; Got: %v", context.DeadlineExceeded, err)
+	}
+
+	// The value pushed after the cancellation should still be available.
+	q.Push(1)
+	if v, ok := q.TryPop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+}
+
+func TestCloseShouldWakeUpAllWaitersAndDrainRemainingValues(t *testing.T) {
+	q := queue.NewBlocking()
+
+	const waiters = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := q.PopWait(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	q.Close()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != queue.ErrClosed {
+			t.Errorf("Expected: %v; Got: %v", queue.ErrClosed, err)
+		}
+	}
+
+	if err := q.Push(1); err != queue.ErrClosed {
+		t.Errorf("Expected: %v; Got: %v", queue.ErrClosed, err)
+	}
+}
+
+func TestCloseShouldKeepRemainingValues(t *testing.T) {
+	q := queue.NewBlocking()
+	q.Push(1)
+	q.Push(2)
+	q.Close()
+
+	for want := 1; want <= 2; want++ {
+		if v, err := q.PopWait(context.Background()); err != nil || v.(int) != want {
+			t.Errorf("Expected: %d; Got: %v, %v", want, v, err)
+		}
+	}
+	if _, err := q.PopWait(context.Background()); err != queue.ErrClosed {
+		t.Errorf("Expected: %v; Got: %v", queue.ErrClosed, err)
+	}
+}
+
+func TestBlockingQueueWithConcurrentProducersAndConsumersShouldDeliverAllValues(t *testing.T) {
+	q := queue.NewBlocking()
+	const producers, consumers = 4, 4
+
+	var pwg, cwg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < pushCount; i++ {
+				q.Push(p*pushCount + i)
+			}
+		}(p)
+	}
+
+	seen := make([][]int, consumers)
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, err := q.PopWait(context.Background())
+				if err != nil {
+					return
+				}
+				seen[c] = append(seen[c], v.(int))
+			}
+		}(c)
+	}
+	pwg.Wait()
+	q.Close()
+	cwg.Wait()
+
+	count := make([]int, producers*pushCount)
+	for _, vs := range seen {
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range vs {
+			count[v]++
+			// Values from the same producer are always received in order.
+			p, i := v/pushCount, v%pushCount
+			if i <= last[p] {
+				t.Errorf("Expected: value after %d; Got: %d", last[p], i)
+			}
+			last[p] = i
+		}
+	}
+	for v, c := range count {
+		if c != 1 {
+			t.Errorf("Expected: value %d received once; Got: %d times", v, c)
+		}
+	}
+}
+
+func TestPopBatchShouldReturnAtMostMaxValues(t *testing.T) {
+	q := queue.NewBlockingWithClock(newFakeClock())
+	for i := 0; i < 10; i++ {
+		q.Push(i)
+	}
+
+	// Full batches are returned right away, without waiting for maxWait.
+	for want := 0; want < 8; want += 4 {
+		batch := q.PopBatch(context.Background(), 4, time.Hour)
+		if len(batch) != 4 {
+			t.Fatalf("Expected: 4 values; Got: %v", batch)
+		}
+		for i, v := range batch {
+			if v.(int) != want+i {
+				t.Errorf("Expected: %d; Got: %d", want+i, v)
+			}
+		}
+	}
+	if l := q.Len(); l != 2 {
+		t.Errorf("Expected: 2; Got: %d", l)
+	}
+}
+
+func TestPopBatchShouldReturnValuesArrivedWithinMaxWait(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewBlockingWithClock(c)
+	result := make(chan []interface{})
+	go func() {
+		result <- q.PopBatch(context.Background(), 500, 20*time.Millisecond)
+	}()
+
+	q.Push(1)
+	c.WaitForTimer()
+	q.Push(2)
+	q.Push(3)
+	c.Advance(19 * time.Millisecond)
+	select {
+	case batch := <-result:
+		t.Fatalf("Expected: no batch before maxWait; Got: %v", batch)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	c.Advance(time.Millisecond)
+	batch := <-result
+	if len(batch) != 3 {
+		t.Fatalf("Expected: 3 values; Got: %v", batch)
+	}
+	for i, v := range batch {
+		if v.(int) != i+1 {
+			t.Errorf("Expected: %d; Got: %d", i+1, v)
+		}
+	}
+}
+
+func TestPopBatchWithZeroMaxWaitShouldReturnAvailableValues(t *testing.T) {
+	q := queue.NewBlocking()
+	q.Push(1)
+	q.Push(2)
+
+	if batch := q.PopBatch(context.Background(), 10, 0); len(batch) != 2 {
+		t.Errorf("Expected: 2 values; Got: %v", batch)
+	}
+	if batch := q.PopBatch(context.Background(), 0, 0); batch != nil {
+		t.Errorf("Expected: nil; Got: %v", batch)
+	}
+}
+
+func TestPopBatchShouldReturnNilWhenCancelledBeforeFirstValue(t *testing.T) {
+	q := queue.NewBlocking()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if batch := q.PopBatch(ctx, 10, time.Hour); batch != nil {
+		t.Errorf("Expected: nil; Got: %v", batch)
+	}
+}
+
+func TestPopBatchShouldReturnGatheredValuesWhenCancelled(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewBlockingWithClock(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Push(1)
+	go func() {
+		c.WaitForTimer()
+		cancel()
+	}()
+
+	if batch := q.PopBatch(ctx, 10, time.Hour); len(batch) != 1 || batch[0].(int) != 1 {
+		t.Errorf("Expected: [1]; Got: %v", batch)
+	}
+}
+
+func TestPopBatchShouldReturnRemainingValuesWhenClosed(t *testing.T) {
+	q := queue.NewBlockingWithClock(newFakeClock())
+	q.Push(1)
+	q.Push(2)
+	q.Close()
+
+	if batch := q.PopBatch(context.Background(), 10, time.Hour); len(batch) != 2 {
+		t.Errorf("Expected: 2 values; Got: %v", batch)
+	}
+	if batch := q.PopBatch(context.Background(), 10, time.Hour); batch != nil {
+		t.Errorf("Expected: nil; Got: %v", batch)
+	}
+}
+
+func TestReadyShouldBeSignalledOnceWhenQueueBecomesNonEmpty(t *testing.T) {
+	q := queue.NewBlocking()
+	ready := q.Ready()
+	select {
+	case <-ready:
+		t.Fatal("Expected: no signal on an empty queue; Got: signal")
+	default:
+	}
+
+	for i := 0; i < 3; i++ {
+		q.Push(i)
+	}
+	select {
+	case <-ready:
+	default:
+		t.Fatal("Expected: signal; Got: none")
+	}
+	select {
+	case <-ready:
+		t.Fatal("Expected: coalesced signals; Got: second signal")
+	default:
+	}
+
+	// Pushing to a non-empty queue doesn't signal again.
+	q.TryPop()
+	q.Push(3)
+	select {
+	case <-ready:
+		t.Fatal("Expected: no signal on a non-empty queue; Got: signal")
+	default:
+	}
+
+	// Once drained, the next push signals again.
+	for {
+		if _, ok := q.TryPop(); !ok {
+			break
+		}
+	}
+	q.Push(4)
+	select {
+	case <-ready:
+	default:
+		t.Fatal("Expected: signal; Got: none")
+	}
+}
+
+func TestReadyShouldBeSignalledWhenFirstCalledOnNonEmptyQueue(t *testing.T) {
+	q := queue.NewBlocking()
+	q.Push(1)
+
+	select {
+	case <-q.Ready():
+	default:
+		t.Fatal("Expected: signal; Got: none")
+	}
+}
+
+func TestReadyShouldAllowSelectingOnManyQueues(t *testing.T) {
+	qs := []*queue.BlockingQueue{queue.NewBlocking(), queue.NewBlocking()}
+	go func() {
+		for i := 0; i < pushCount; i++ {
+			qs[i%2].Push(i)
+		}
+	}()
+
+	count := 0
+	timeout := time.After(10 * time.Second)
+	for count < pushCount {
+		select {
+		case <-qs[0].Ready():
+		case <-qs[1].Ready():
+		case <-timeout:
+			t.Fatalf("Expected: %d values; Got: %d", pushCount, count)
+		}
+		for _, q := range qs {
+			for {
+				if _, ok := q.TryPop(); !ok {
+					break
+				}
+				count++
+			}
+		}
+	}
+}
+
+func TestPopAnyShouldReturnValueAndIndexOfNonEmptyQueue(t *testing.T) {
+	qs := []*queue.BlockingQueue{queue.NewBlocking(), queue.NewBlocking(), queue.NewBlocking()}
+	qs[1].Push(1)
+
+	if i, v, err := queue.PopAny(context.Background(), qs...); i != 1 || v.(int) != 1 || err != nil {
+		t.Errorf("Expected: 1, 1, nil; Got: %d, %v, %v", i, v, err)
+	}
+}
+
+func TestPopAnyShouldWaitForValuePushedLater(t *testing.T) {
+	qs := []*queue.BlockingQueue{queue.NewBlocking(), queue.NewBlocking()}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		qs[1].Push(1)
+	}()
+
+	if i, v, err := queue.PopAny(context.Background(), qs...); i != 1 || v.(int) != 1 || err != nil {
+		t.Errorf("Expected: 1, 1, nil; Got: %d, %v, %v", i, v, err)
+	}
+}
+
+func TestPopAnyShouldSpreadValuesAmongReadyQueues(t *testing.T) {
+	const queues, rounds = 3, 100
+	qs := make([]*queue.BlockingQueue, queues)
+	for i := range qs {
+		qs[i] = queue.NewBlocking()
+		for j := 0; j < rounds; j++ {
+			qs[i].Push(j)
+		}
+	}
+
+	// Each queue is expected to serve a third of the values; allow a wide
+	// margin, as the queue tried first is picked at random.
+	count := make([]int, queues)
+	for i := 0; i < rounds; i++ {
+		j, _, err := queue.PopAny(context.Background(), qs...)
+		if err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+		count[j]++
+	}
+	for i, c := range count {
+		if c < rounds/queues/3 {
+			t.Errorf("Expected: at least %d values from queue %d; Got: %d", rounds/queues/3, i, c)
+		}
+	}
+}
+
+func TestPopAnyShouldNotStarveQueuesWhenCallsWithOverlappingSetsInterleave(t *testing.T) {
+	const rounds = 100
+	x, y, z := queue.NewBlocking(), queue.NewBlocking(), queue.NewBlocking()
+	for _, q := range []*queue.BlockingQueue{x, y, z} {
+		for j := 0; j < rounds; j++ {
+			q.Push(j)
+		}
+	}
+
+	// Two consumers share the first queue and call PopAny in turn.
+	count := make([]int, 3)
+	for i := 0; i < rounds/2; i++ {
+		j, _, err := queue.PopAny(context.Background(), x, y)
+		if err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+		count[j]++
+		j, _, err = queue.PopAny(context.Background(), x, z)
+		if err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+		if j == 1 {
+			count[2]++
+		}
+	}
+	for i, name := range []string{"x", "y", "z"} {
+		if count[i] < rounds/10 {
+			t.Errorf("Expected: at least %d values from queue %s; Got: %d", rounds/10, name, count[i])
+		}
+	}
+}
+
+func TestPopAnyShouldNotStarveQueuesWhenOtherQueueIsAlwaysReady(t *testing.T) {
+	busy, idle := queue.NewBlocking(), queue.NewBlocking()
+	busy.Push(0)
+	idle.Push(1)
+
+	for i := 0; i < 100; i++ {
+		j, _, err := queue.PopAny(context.Background(), busy, idle)
+		if err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+		if j == 1 {
+			return
+		}
+		// Keep the busy queue always ready.
+		busy.Push(0)
+	}
+	t.Error("Expected: value from the idle queue within 100 calls; Got: none")
+}
+
+func TestPopAnyShouldSkipClosedQueues(t *testing.T) {
+	qs := []*queue.BlockingQueue{queue.NewBlocking(), queue.NewBlocking()}
+	qs[0].Push(1)
+	qs[0].Close()
+
+	// Remaining values of closed queues are still returned.
+	if i, v, err := queue.PopAny(context.Background(), qs...); i != 0 || v.(int) != 1 || err != nil {
+		t.Errorf("Expected: 0, 1, nil; Got: %d, %v, %v", i, v, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		qs[1].Push(2)
+	}()
+	if i, v, err := queue.PopAny(context.Background(), qs...); i != 1 || v.(int) != 2 || err != nil {
+		t.Errorf("Expected: 1, 2, nil; Got: %d, %v, %v", i, v, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		qs[1].Close()
+	}()
+	if i, _, err := queue.PopAny(context.Background(), qs...); i != -1 || err != queue.ErrClosed {
+		t.Errorf("Expected: -1, %v; Got: %d, %v", queue.ErrClosed, i, err)
+	}
+	if i, _, err := queue.PopAny(context.Background()); i != -1 || err != queue.ErrClosed {
+		t.Errorf("Expected: -1, %v; Got: %d, %v", queue.ErrClosed, i, err)
+	}
+}
+
+func TestPopAnyShouldReturnContextErrorWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if i, _, err := queue.PopAny(ctx, queue.NewBlocking(), queue.NewBlocking()); i != -1 || err != context.DeadlineExceeded {
+		t.Errorf("Expected: -1, %v; Got: %d, %v", context.DeadlineExceeded, i, err)
+	}
+}
+
+func TestReadyShouldBeClosedWhenQueueIsClosed(t *testing.T) {
+	q := queue.NewBlocking()
+	ready := q.Ready()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Push(1)
+		q.Close()
+	}()
+
+	count := 0
+	for {
+		select {
+		case _, ok := <-ready:
+			for {
+				if _, popped := q.TryPop(); !popped {
+					break
+				}
+				count++
+			}
+			if !ok {
+				if count != 1 {
+					t.Errorf("Expected: 1 value; Got: %d", count)
+				}
+				// Calling Ready after Close returns a closed channel as well.
+				closed := queue.NewBlocking()
+				closed.Close()
+				if _, ok := <-closed.Ready(); ok {
+					t.Error("Expected: closed channel; Got: open")
+				}
+				return
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Expected: Ready closed; Got: blocked")
+		}
+	}
+}
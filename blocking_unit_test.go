@@ -0,0 +1,107 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlockingQueuePushShouldWakeUpASingleWaiter(t *testing.T) {
+	q := NewBlocking()
+	const waiters = 3
+	done := make(chan struct{}, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			q.PopWait(context.Background())
+			done <- struct{}{}
+		}()
+	}
+	waitingLen := func() int {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.waiters.Len()
+	}
+	for waitingLen() < waiters {
+		time.Sleep(time.Millisecond)
+	}
+
+	q.Push(1)
+	<-done
+	q.mu.Lock()
+	if l := q.waiters.Len(); l != waiters-1 {
+		t.Errorf("Expected: %d waiters; Got: %d", waiters-1, l)
+	}
+	for i := 0; i < q.waiters.Len(); i++ {
+		if w := q.waiters.At(i).(*waiter); w.woken || len(w.c) != 0 {
+			t.Error("Expected: the other waiters not to be woken up")
+		}
+	}
+	q.mu.Unlock()
+	q.Close()
+	for i := 1; i < waiters; i++ {
+		<-done
+	}
+}
+
+func TestBlockingQueueCancelWaiterShouldPassWakeUpOn(t *testing.T) {
+	q := NewBlocking()
+	q.mu.Lock()
+	a, b := q.addWaiter(), q.addWaiter()
+	q.q.Push(1)
+	q.wakeOne()
+	q.mu.Unlock()
+
+	// a gives up after being woken up, so b must be woken up instead.
+	q.cancelWaiter(a)
+	select {
+	case <-b.c:
+	default:
+		t.Error("Expected: b woken up; Got: not woken up")
+	}
+}
+
+func TestBlockingQueueCancelledWaitersShouldNotAccumulate(t *testing.T) {
+	q := NewBlocking()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < pushCount; i++ {
+		q.PopWait(ctx)
+	}
+	if l := q.waiters.Len(); l > 1 {
+		t.Errorf("Expected: at most 1 waiter; Got: %d", l)
+	}
+}
+
+func TestPopAnyShouldNotLeaveWaitersBehindWhenReturningValue(t *testing.T) {
+	qs := []*BlockingQueue{NewBlocking(), NewBlocking()}
+	// Whatever queue is tried first, some call scans the empty queue first.
+	for i := 0; i < 100; i++ {
+		qs[1].Push(i)
+		if _, _, err := PopAny(context.Background(), qs...); err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+	}
+	if l := qs[0].waiters.Len() - qs[0].cancelled; l != 0 {
+		t.Errorf("Expected: no waiters left; Got: %d", l)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// OverflowPolicy determines what a bounded queue does when a value is pushed
+// into it while it's full.
+type OverflowPolicy int
+
+const (
+	// RejectNew rejects the pushed value, leaving the queue unchanged.
+	// Push returns false for the rejected value.
+	RejectNew OverflowPolicy = iota
+
+	// DropOldest drops the value at the front of the queue to make room for the
+	// pushed value, overwriting the oldest values just like a ring buffer.
+	DropOldest
+
+	// DropNewest drops the value at the back of the queue, which is the most
+	// recently pushed value, to make room for the pushed value.
+	DropNewest
+)
+
+// Bounded implements a First-In-First-Out (FIFO) queue that holds at most a
+// fixed number of values. When full, the queue applies its overflow policy to
+// every new pushed value.
+// Bounded uses the same dynamic growing circular linked list of arrays as Queue,
+// so memory is only allocated as values are pushed, not upfront for its capacity.
+type Bounded struct {
+	// q holds the queue values.
+	q Queue
+
+	// cap holds the maximum number of values the queue can hold.
+	cap int
+
+	// policy holds the policy applied to values pushed into a full queue.
+	policy OverflowPolicy
+
+	// dropped holds the number of values discarded by the overflow policy.
+	dropped int
+}
+
+// NewBounded returns an initialized bounded queue that holds at most capacity
+// values, applying policy when full.
+// NewBounded panics if capacity is less than 1 or policy is unknown.
+func NewBounded(capacity int, policy OverflowPolicy) *Bounded {
+	if capacity < 1 {
+		panic("queue: bounded capacity must be greater than zero")
+	}
+	if policy < RejectNew || policy > DropNewest {
+		panic("queue: unknown overflow policy")
+	}
+	return &Bounded{cap: capacity, policy: policy}
+}
+
+// Len returns the number of elements of queue d.
+// The complexity is O(1).
+func (d *Bounded) Len() int { return d.q.Len() }
+
+// Cap returns the maximum number of elements queue d can hold.
+// The complexity is O(1).
+func (d *Bounded) Cap() int { return d.cap }
+
+// Dropped returns the number of values discarded by the overflow policy so far,
+// including the values rejected by the RejectNew policy.
+// The complexity is O(1).
+func (d *Bounded) Dropped() int { return d.dropped }
+
+// Front returns the first element of queue d or nil if the queue is empty.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *Bounded) Front() (interface{}, bool) { return d.q.Front() }
+
+// Push adds value v to the the back of the queue.
+// If the queue is full, the overflow policy is applied first. The result
+// indicates whether v was added to the queue, which is false only when
+// v is rejected by the RejectNew policy.
+// The complexity is O(1).
+func (d *Bounded) Push(v interface{}) bool {
+	if d.q.Len() >= d.cap {
+		d.dropped++
+		switch d.policy {
+		case DropOldest:
+			d.q.Pop()
+		case DropNewest:
+			d.q.PopBack()
+		default:
+			return false
+		}
+	}
+	d.q.Push(v)
+	return true
+}
+
+// Pop retrieves and removes the current element from the front of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *Bounded) Pop() (interface{}, bool) { return d.q.Pop() }
@@ -0,0 +1,122 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestNewBoundedWithInvalidArgumentsShouldPanic(t *testing.T) {
+	tests := map[string]func(){
+		"zero capacity":    func() { queue.NewBounded(0, queue.RejectNew) },
+		"unknown policy":   func() { queue.NewBounded(1, queue.OverflowPolicy(-1)) },
+		"unknown policy 2": func() { queue.NewBounded(1, queue.DropNewest+1) },
+	}
+	for name, f := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("Expected: panic; Got: none")
+				}
+			}()
+			f()
+		})
+	}
+}
+
+func TestBoundedWhenEmptyShouldReturnAsEmpty(t *testing.T) {
+	q := queue.NewBounded(2, queue.RejectNew)
+
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+	if c := q.Cap(); c != 2 {
+		t.Errorf("Expected: 2; Got: %d", c)
+	}
+}
+
+func TestBoundedOverflowPoliciesShouldKeepExpectedValues(t *testing.T) {
+	tests := map[queue.OverflowPolicy]struct {
+		name     string
+		accepted []bool
+		want     []int
+	}{
+		queue.RejectNew:  {"RejectNew", []bool{true, true, true, false, false}, []int{0, 1, 2}},
+		queue.DropOldest: {"DropOldest", []bool{true, true, true, true, true}, []int{2, 3, 4}},
+		queue.DropNewest: {"DropNewest", []bool{true, true, true, true, true}, []int{0, 1, 4}},
+	}
+	for policy, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := queue.NewBounded(3, policy)
+			for i, want := range test.accepted {
+				if got := q.Push(i); got != want {
+					t.Errorf("Push(%d): Expected: %t; Got: %t", i, want, got)
+				}
+				if q.Len() > q.Cap() {
+					t.Errorf("Expected: at most %d; Got: %d", q.Cap(), q.Len())
+				}
+			}
+			if d := q.Dropped(); d != 2 {
+				t.Errorf("Expected: 2 dropped; Got: %d", d)
+			}
+			if v, ok := q.Front(); !ok || v.(int) != test.want[0] {
+				t.Errorf("Expected: %d; Got: %d", test.want[0], v)
+			}
+			for _, want := range test.want {
+				if v, ok := q.Pop(); !ok || v.(int) != want {
+					t.Errorf("Expected: %d; Got: %d", want, v)
+				}
+			}
+			if q.Len() != 0 {
+				t.Errorf("Expected: 0; Got: %d", q.Len())
+			}
+		})
+	}
+}
+
+func TestBoundedDropOldestShouldRetrieveLastCapElementsInOrder(t *testing.T) {
+	q := queue.NewBounded(pushCount/2, queue.DropOldest)
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := pushCount / 2; j < pushCount; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		if q.Len() != 0 {
+			t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+		}
+	}
+	if d := q.Dropped(); d != refillCount*pushCount/2 {
+		t.Errorf("Expected: %d dropped; Got: %d", refillCount*pushCount/2, d)
+	}
+}
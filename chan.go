@@ -0,0 +1,91 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "sync/atomic"
+
+// Chan implements an unbounded channel: values sent to In are buffered in a
+// Queue by an internal goroutine and delivered in order to Out, so senders
+// never block waiting for receivers.
+//
+// Closing In closes Out once all buffered values are delivered, which is also
+// when the internal goroutine exits. So to avoid leaking the goroutine, always
+// close In and receive all values from Out.
+type Chan struct {
+	// In is the channel values are sent to.
+	In chan<- interface{}
+
+	// Out is the channel values are received from.
+	Out <-chan interface{}
+
+	// len holds the number of values sent to In but not yet received from Out.
+	len atomic.Int64
+}
+
+// NewChan returns an initialized unbounded channel.
+func NewChan() *Chan {
+	in := make(chan interface{})
+	out := make(chan interface{})
+	c := &Chan{In: in, Out: out}
+	go c.run(in, out)
+	return c
+}
+
+// Len returns the number of values sent to c.In but not yet received from c.Out.
+// Len is updated by the internal goroutine right after each value is received
+// from In or sent to Out, so it may briefly lag behind the senders and receivers.
+// The complexity is O(1).
+func (c *Chan) Len() int { return int(c.len.Load()) }
+
+// run buffers the values received from in and sends them to out, closing out
+// once in is closed and all buffered values are sent.
+func (c *Chan) run(in <-chan interface{}, out chan<- interface{}) {
+	defer close(out)
+	var q Queue
+	for {
+		if q.Len() == 0 {
+			v, ok := <-in
+			if !ok {
+				return
+			}
+			c.len.Add(1)
+			q.Push(v)
+			continue
+		}
+
+		v, _ := q.Front()
+		select {
+		case nv, ok := <-in:
+			if !ok {
+				for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+					out <- v
+					c.len.Add(-1)
+				}
+				return
+			}
+			c.len.Add(1)
+			q.Push(nv)
+		case out <- v:
+			c.len.Add(-1)
+			q.Pop()
+		}
+	}
+}
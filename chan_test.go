@@ -0,0 +1,112 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestChanShouldNeverBlockSenders(t *testing.T) {
+	c := queue.NewChan()
+
+	for i := 0; i < pushCount; i++ {
+		c.In <- i
+	}
+	if !eventually(func() bool { return c.Len() == pushCount }) {
+		t.Errorf("Expected: %d; Got: %d", pushCount, c.Len())
+	}
+	close(c.In)
+
+	for i := 0; i < pushCount; i++ {
+		if v, ok := <-c.Out; !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %v", i, v)
+		}
+	}
+	if v, ok := <-c.Out; ok {
+		t.Errorf("Expected: closed channel; Got: %v", v)
+	}
+	if l := c.Len(); l != 0 {
+		t.Errorf("Expected: 0; Got: %d", l)
+	}
+}
+
+func TestChanWithConcurrentSenderAndReceiverShouldDeliverAllValuesInOrder(t *testing.T) {
+	c := queue.NewChan()
+	count := pushCount * refillCount
+
+	go func() {
+		for i := 0; i < count; i++ {
+			c.In <- i
+		}
+		close(c.In)
+	}()
+	i := 0
+	for v := range c.Out {
+		if v.(int) != i {
+			t.Fatalf("Expected: %d; Got: %v", i, v)
+		}
+		i++
+	}
+	if i != count {
+		t.Errorf("Expected: %d; Got: %d", count, i)
+	}
+}
+
+func TestChanShouldCloseOutWhenInIsClosedEmpty(t *testing.T) {
+	c := queue.NewChan()
+	close(c.In)
+	if v, ok := <-c.Out; ok {
+		t.Errorf("Expected: closed channel; Got: %v", v)
+	}
+}
+
+func TestChanShouldNotLeakGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < refillCount; i++ {
+		c := queue.NewChan()
+		c.In <- 1
+		c.In <- 2
+		<-c.Out
+		close(c.In)
+		for range c.Out {
+		}
+	}
+
+	// The goroutines may take a moment to exit after closing Out.
+	if !eventually(func() bool { return runtime.NumGoroutine() <= before }) {
+		t.Errorf("Expected: at most %d goroutines; Got: %d", before, runtime.NumGoroutine())
+	}
+}
+
+// eventually reports whether cond becomes true within a second.
+func eventually(cond func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
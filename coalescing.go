@@ -0,0 +1,91 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// CoalescingQueue implements a First-In-First-Out (FIFO) queue that holds at
+// most one pending value per key, as returned by a user supplied key function.
+// Pushing a value whose key is already pending merges it into the pending
+// value, using a user supplied merge function, without changing the pending
+// value position in the queue. This allows, for instance, metric deltas or
+// state patches to be aggregated until a consumer gets to them.
+//
+// CoalescingQueue shares the storage of UniqueQueue: the pending values are
+// held in a Queue, so their positions never change, and in a map by key.
+//
+// CoalescingQueue is not safe for concurrent use.
+// CoalescingQueue must be created with NewCoalescing.
+type CoalescingQueue struct {
+	// q holds the pending values.
+	q keyedQueue
+
+	// merge merges a pushed value into the pending value with the same key.
+	merge func(old, new interface{}) interface{}
+}
+
+// NewCoalescing returns an initialized coalescing queue that uses key to get
+// the key of its values, and merge to merge a pushed value (new) into the
+// pending value with the same key (old), returning the merged value.
+// Keys must be comparable.
+// NewCoalescing panics if key or merge is nil.
+func NewCoalescing(key func(v interface{}) interface{}, merge func(old, new interface{}) interface{}) *CoalescingQueue {
+	if merge == nil {
+		panic("queue: nil merge function")
+	}
+	return &CoalescingQueue{q: newKeyedQueue(key), merge: merge}
+}
+
+// Len returns the number of pending elements of queue d.
+// The complexity is O(1).
+func (d *CoalescingQueue) Len() int { return d.q.len() }
+
+// Contains returns whether there's a pending element with key in queue d.
+// The complexity is O(1).
+func (d *CoalescingQueue) Contains(key interface{}) bool { return d.q.contains(key) }
+
+// Front returns the first element of queue d or nil if the queue is empty.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1), plus O(1) per discarded removed element.
+func (d *CoalescingQueue) Front() (interface{}, bool) { return d.q.front() }
+
+// Push adds value v to the the back of the queue, unless an element with the
+// same key is pending, in which case v is merged into the pending element,
+// keeping its position in the queue.
+// The result indicates whether v was added to the back of the queue.
+// The complexity is O(1), plus the merge function complexity.
+func (d *CoalescingQueue) Push(v interface{}) bool {
+	e, added := d.q.push(v)
+	if !added {
+		e.v = d.merge(e.v, v)
+	}
+	return added
+}
+
+// Pop retrieves and removes the current element from the front of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1), plus O(1) per discarded removed element.
+func (d *CoalescingQueue) Pop() (interface{}, bool) { return d.q.pop() }
+
+// Remove removes the pending element with key from queue d.
+// The result indicates whether an element was removed.
+// The complexity is amortized O(1).
+func (d *CoalescingQueue) Remove(key interface{}) bool { return d.q.remove(key) }
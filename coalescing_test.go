@@ -0,0 +1,111 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+// delta represents a metric delta, identified by the metric name.
+type delta struct {
+	metric string
+	value  int
+}
+
+func deltaMetric(v interface{}) interface{} { return v.(delta).metric }
+
+func sumDeltas(old, new interface{}) interface{} {
+	return delta{metric: old.(delta).metric, value: old.(delta).value + new.(delta).value}
+}
+
+func TestCoalescingQueueShouldMergePendingValuesInPlace(t *testing.T) {
+	q := queue.NewCoalescing(deltaMetric, sumDeltas)
+	if !q.Push(delta{"a", 1}) || !q.Push(delta{"b", 10}) {
+		t.Fatal("Expected: true; Got: false")
+	}
+	for i := 0; i < pushCount; i++ {
+		if q.Push(delta{"a", 1}) {
+			t.Fatal("Expected: false as a is pending; Got: true")
+		}
+	}
+	q.Push(delta{"c", 100})
+	q.Push(delta{"b", 10})
+	if l := q.Len(); l != 3 {
+		t.Errorf("Expected: 3; Got: %d", l)
+	}
+	if !q.Contains("b") || q.Contains("d") {
+		t.Error("Expected: b pending and d not pending")
+	}
+
+	for _, want := range []delta{{"a", pushCount + 1}, {"b", 20}, {"c", 100}} {
+		if v, ok := q.Front(); !ok || v.(delta) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+		if v, ok := q.Pop(); !ok || v.(delta) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+
+	// Popped keys start over.
+	q.Push(delta{"a", 1})
+	if v, _ := q.Pop(); v.(delta) != (delta{"a", 1}) {
+		t.Errorf("Expected: %v; Got: %v", delta{"a", 1}, v)
+	}
+}
+
+func TestCoalescingQueueRemoveShouldRemovePendingValue(t *testing.T) {
+	q := queue.NewCoalescing(deltaMetric, sumDeltas)
+	q.Push(delta{"a", 1})
+	q.Push(delta{"b", 1})
+
+	if !q.Remove("a") || q.Remove("a") {
+		t.Error("Expected: a removed once")
+	}
+	// Pushing a removed key starts over, at the back of the queue.
+	q.Push(delta{"a", 5})
+	for _, want := range []delta{{"b", 1}, {"a", 5}} {
+		if v, ok := q.Pop(); !ok || v.(delta) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+	}
+}
+
+func TestNewCoalescingWithNilFunctionsShouldPanic(t *testing.T) {
+	tests := map[string]func(){
+		"nil key":   func() { queue.NewCoalescing(nil, sumDeltas) },
+		"nil merge": func() { queue.NewCoalescing(deltaMetric, nil) },
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("Expected: panic; Got: none")
+				}
+			}()
+			test()
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build queuedebug
+
+package queue
+
+import (
+	"fmt"
+	"sync"
+)
+
+// debug enables the detection of unsynchronized concurrent use of Queue.
+// Build with the queuedebug tag to enable it.
+const debug = true
+
+// inFlightOps describes the operations in progress on a queue: either a
+// single writer or any number of readers.
+type inFlightOps struct {
+	// writer holds the name of the operation modifying the queue, if any.
+	writer string
+
+	// reader holds the name of one of the operations reading the queue, if any.
+	reader string
+
+	// readers holds the number of operations reading the queue.
+	readers int
+}
+
+var (
+	// inFlightMu guards inFlight.
+	inFlightMu sync.Mutex
+
+	// inFlight maps each queue with operations in progress to those
+	// operations.
+	inFlight = make(map[*Queue]inFlightOps)
+)
+
+// debugEnter marks operation op, which modifies queue d, as in progress,
+// panicking if any other operation is already in progress, which means d is
+// being used concurrently without synchronization.
+func (d *Queue) debugEnter(op string) {
+	inFlightMu.Lock()
+	defer inFlightMu.Unlock()
+	ops := inFlight[d]
+	switch {
+	case ops.writer != "":
+		panic(concurrentUse(d, op, ops.writer))
+	case ops.readers > 0:
+		panic(concurrentUse(d, op, ops.reader))
+	}
+	inFlight[d] = inFlightOps{writer: op}
+}
+
+// debugExit checks the invariants of queue d, panicking if any of them was
+// violated, and marks its modifying operation as completed.
+func (d *Queue) debugExit() {
+	defer func() {
+		inFlightMu.Lock()
+		delete(inFlight, d)
+		inFlightMu.Unlock()
+	}()
+	d.debugCheck()
+}
+
+// debugEnterRead marks operation op, which only reads queue d, as in progress,
+// panicking if an operation modifying d is already in progress. Any number of
+// reading operations may be in progress at the same time.
+func (d *Queue) debugEnterRead(op string) {
+	inFlightMu.Lock()
+	defer inFlightMu.Unlock()
+	ops := inFlight[d]
+	if ops.writer != "" {
+		panic(concurrentUse(d, op, ops.writer))
+	}
+	ops.reader = op
+	ops.readers++
+	inFlight[d] = ops
+}
+
+// debugExitRead checks the invariants of queue d, panicking if any of them
+// was violated, and marks one of its reading operations as completed.
+func (d *Queue) debugExitRead() {
+	defer func() {
+		inFlightMu.Lock()
+		if ops := inFlight[d]; ops.readers > 1 {
+			ops.readers--
+			inFlight[d] = ops
+		} else {
+			delete(inFlight, d)
+		}
+		inFlightMu.Unlock()
+	}()
+	d.debugCheck()
+}
+
+// debugCheck panics if any of the invariants of queue d was violated.
+func (d *Queue) debugCheck() {
+	if err := d.checkInvariants(); err != nil {
+		panic(fmt.Sprintf("queue: corrupted Queue %p: %v", d, err))
+	}
+}
+
+// concurrentUse returns the panic message for operation op being called on
+// queue d while operation other is in progress.
+func concurrentUse(d *Queue, op, other string) string {
+	return fmt.Sprintf("queue: concurrent use of Queue %p: %s called while %s is in progress; Queue is not safe for concurrent use", d, op, other)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue implements a queue where each value only becomes visible at its
+// due time. Values are popped in due time order and values with the same due
+// time are popped in the order they were pushed (FIFO), just like a Queue.
+//
+// The values are held in a heap ordered by due time and, for the same due
+// time, by push order, so pushing and popping are O(log n).
+// DelayQueue is safe for concurrent use.
+// The zero value for DelayQueue is an empty queue ready to use.
+type DelayQueue struct {
+	// mu protects all the other fields.
+	mu sync.Mutex
+
+	// index holds the values, earliest due first.
+	index binaryHeap[delayEntry]
+
+	// seq holds the sequence number of the next pushed value.
+	seq uint64
+
+	// wait is closed, and then cleared, to wake up all consumers waiting for
+	// a value when a value with an earlier due time is pushed.
+	// A nil wait means there are no waiting consumers.
+	wait chan struct{}
+
+	// clock holds the clock used to tell whether values are due.
+	// A nil clock means the system clock is used.
+	clock Clock
+}
+
+// delayEntry holds a value in a DelayQueue.
+type delayEntry struct {
+	// v holds the value.
+	v interface{}
+
+	// due holds the time the value becomes visible.
+	due time.Time
+
+	// seq holds the sequence number of the value, which orders the values
+	// with the same due time.
+	seq uint64
+}
+
+// before reports whether entry e must be popped before entry o.
+func (e delayEntry) before(o delayEntry) bool {
+	if !e.due.Equal(o.due) {
+		return e.due.Before(o.due)
+	}
+	return e.seq < o.seq
+}
+
+// NewDelay returns an initialized delay queue.
+func NewDelay() *DelayQueue {
+	return new(DelayQueue)
+}
+
+// NewDelayWithClock returns an initialized delay queue that uses clock c to
+// tell whether values are due.
+func NewDelayWithClock(c Clock) *DelayQueue {
+	return &DelayQueue{clock: c}
+}
+
+// Len returns the number of elements of queue d, including the elements not
+// due yet.
+// The complexity is O(1).
+func (d *DelayQueue) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.index)
+}
+
+// PushAt adds value v to the queue, to become visible at time t.
+// Values with a due time in the past are visible right away.
+// The complexity is O(log n).
+func (d *DelayQueue) PushAt(v interface{}, t time.Time) {
+	e := delayEntry{v: v, due: t}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e.seq = d.seq
+	d.seq++
+	d.index.push(e)
+	if d.index[0].seq == e.seq {
+		// v is the next value due, so the waiting consumers need to be
+		// woken up to wait for it instead.
+		d.notify()
+	}
+}
+
+// PushAfter adds value v to the queue, to become visible once duration
+// delay elapses.
+// The complexity is the same as PushAt.
+func (d *DelayQueue) PushAfter(v interface{}, delay time.Duration) {
+	d.PushAt(v, d.getClock().Now().Add(delay))
+}
+
+// TryPop retrieves and removes the element with the earliest due time if it is
+// due, without waiting.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty or no element is due, false will be returned.
+// The complexity is O(log n).
+func (d *DelayQueue) TryPop() (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	v, ok, _ := d.pop()
+	return v, ok
+}
+
+// Pop retrieves and removes the element with the earliest due time, waiting
+// for it to be due, or for a value to be pushed if the queue is empty.
+// If ctx is done before a value is due, ctx.Err() is returned.
+// The complexity is the same as TryPop.
+func (d *DelayQueue) Pop(ctx context.Context) (interface{}, error) {
+	for {
+		d.mu.Lock()
+		v, ok, wait := d.pop()
+		if ok {
+			d.mu.Unlock()
+			return v, nil
+		}
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = d.getClock().After(wait)
+		}
+		w := d.waiter()
+		d.mu.Unlock()
+
+		select {
+		case <-w:
+		case <-timer:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// pop retrieves and removes the element with the earliest due time if it is
+// due. If there is no due element, the time until the earliest due time is
+// returned, or zero if the queue is empty.
+// d.mu must be held.
+func (d *DelayQueue) pop() (interface{}, bool, time.Duration) {
+	if len(d.index) == 0 {
+		return nil, false, 0
+	}
+	due := d.index[0].due
+	if now := d.getClock().Now(); now.Before(due) {
+		return nil, false, due.Sub(now)
+	}
+	return d.index.pop().v, true, 0
+}
+
+// getClock returns the clock used by queue d.
+func (d *DelayQueue) getClock() Clock {
+	if d.clock == nil {
+		return systemClock{}
+	}
+	return d.clock
+}
+
+// waiter returns the channel closed when a value with an earlier due time is
+// pushed.
+// d.mu must be held.
+func (d *DelayQueue) waiter() chan struct{} {
+	if d.wait == nil {
+		d.wait = make(chan struct{})
+	}
+	return d.wait
+}
+
+// notify wakes up all waiting consumers.
+// d.mu must be held.
+func (d *DelayQueue) notify() {
+	if d.wait != nil {
+		close(d.wait)
+		d.wait = nil
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestDelayQueueWithZeroValueShouldReturnReadyToUseQueue(t *testing.T) {
+	var q queue.DelayQueue
+
+	if _, ok := q.TryPop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	q.PushAfter(1, 0)
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+	if v, ok := q.TryPop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+}
+
+func TestDelayQueueShouldReturnValuesOnlyOnceDue(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewDelayWithClock(c)
+	q.PushAfter(1, 30*time.Second)
+	if _, ok := q.TryPop(); ok {
+		t.Error("Expected: false as no value is due; Got: true")
+	}
+
+	result := make(chan interface{})
+	go func() {
+		v, _ := q.Pop(context.Background())
+		result <- v
+	}()
+	c.WaitForTimer()
+	c.Advance(29 * time.Second)
+	select {
+	case v := <-result:
+		t.Fatalf("Expected: no value before it is due; Got: %v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	c.Advance(time.Second)
+	if v := <-result; v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0; Got: %d", l)
+	}
+}
+
+func TestDelayQueueShouldReturnValuesInDueTimeOrderAndFIFOForSameDueTime(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewDelayWithClock(c)
+	now := c.Now()
+	for i := 0; i < pushCount; i++ {
+		// Push 3 due times, latest first.
+		q.PushAt(i, now.Add(time.Duration(3-i%3)*time.Second))
+	}
+	c.Advance(3 * time.Second)
+
+	for r := 2; r >= 0; r-- {
+		last := -1
+		for i := 0; i < pushCount/3; i++ {
+			v, err := q.Pop(context.Background())
+			if err != nil {
+				t.Fatalf("Expected: nil error; Got: %v", err)
+			}
+			if v.(int)%3 != r || v.(int) <= last {
+				t.Fatalf("Expected: value after %d with remainder %d; Got: %d", last, r, v)
+			}
+			last = v.(int)
+		}
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+}
+
+func TestDelayQueueShouldReturnValuesDueInThePastRightAway(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewDelayWithClock(c)
+	q.PushAt(2, c.Now().Add(-time.Second))
+	q.PushAt(1, c.Now().Add(-time.Hour))
+
+	for want := 1; want <= 2; want++ {
+		if v, ok := q.TryPop(); !ok || v.(int) != want {
+			t.Errorf("Expected: %d; Got: %v", want, v)
+		}
+	}
+}
+
+func TestDelayQueueShouldNotReturnValuesDueInTheFarFuture(t *testing.T) {
+	q := queue.NewDelay()
+	q.PushAt(1, time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC))
+	q.PushAfter(2, math.MaxInt64)
+
+	if v, ok := q.TryPop(); ok {
+		t.Errorf("Expected: no value due; Got: %v", v)
+	}
+	if l := q.Len(); l != 2 {
+		t.Errorf("Expected: 2; Got: %d", l)
+	}
+}
+
+func TestDelayQueuePopShouldWakeUpWhenEarlierValueIsPushed(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewDelayWithClock(c)
+	q.PushAfter("later", time.Minute)
+
+	result := make(chan interface{})
+	go func() {
+		v, _ := q.Pop(context.Background())
+		result <- v
+	}()
+	c.WaitForTimer()
+	q.PushAfter("sooner", 10*time.Second)
+	c.WaitForTimer()
+	c.Advance(10 * time.Second)
+
+	if v := <-result; v != "sooner" {
+		t.Errorf("Expected: sooner; Got: %v", v)
+	}
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+}
+
+func TestDelayQueuePopShouldWaitForValuePushedLater(t *testing.T) {
+	q := queue.NewDelay()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.PushAfter(1, 10*time.Millisecond)
+	}()
+
+	if v, err := q.Pop(context.Background()); err != nil || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v, %v", v, err)
+	}
+}
+
+func TestDelayQueuePopShouldReturnContextErrorWhenCancelled(t *testing.T) {
+	q := queue.NewDelayWithClock(newFakeClock())
+	q.PushAfter(1, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.Pop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected: %v; Got: %v", context.DeadlineExceeded, err)
+	}
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+}
+
+func TestDelayQueuePushAtShouldNotAllocateForDistinctDueTimes(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewDelayWithClock(c)
+	now := c.Now()
+	for i := 0; i < pushCount; i++ {
+		q.PushAt(nil, now)
+	}
+	for i := 0; i < pushCount; i++ {
+		q.TryPop()
+	}
+
+	// The heap has room for pushCount values, so every push reuses it, even
+	// with a different due time each.
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		for j := 0; j < pushCount; j++ {
+			i++
+			q.PushAt(nil, now.Add(-time.Duration(i)))
+		}
+		for j := 0; j < pushCount; j++ {
+			q.TryPop()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected: 0 allocations; Got: %v", allocs)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package generic_test
+
+import (
+	"testing"
+
+	"github.com/ef-ds/queue/generic"
+)
+
+func TestPopWithZeroValueShouldReturnReadyToUsequeue(t *testing.T) {
+	var q generic.Queue[int]
+	q.Push(1)
+	q.Push(2)
+
+	v, ok := q.Front()
+	if !ok || v != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	v, ok = q.Pop()
+	if !ok || v != 1 {
+		t.Errorf("Expected: 1; Got: %d", v)
+	}
+	v, ok = q.Front()
+	if !ok || v != 2 {
+		t.Errorf("Expected: 2; Got: %d", v)
+	}
+	v, ok = q.Pop()
+	if !ok || v != 2 {
+		t.Errorf("Expected: 2; Got: %d", v)
+	}
+	_, ok = q.Front()
+	if ok {
+		t.Error("Expected: empty slice (ok=false); Got: ok=true")
+	}
+	_, ok = q.Pop()
+	if ok {
+		t.Error("Expected: empty slice (ok=false); Got: ok=true")
+	}
+}
+
+func TestWithZeroValueAndEmptyShouldReturnAsEmpty(t *testing.T) {
+	var q generic.Queue[int]
+
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestInitShouldReturnEmptyqueue(t *testing.T) {
+	var q generic.Queue[int]
+	q.Push(1)
+
+	q.Init()
+
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestPopWithNilValuesShouldReturnAllValuesInOrder(t *testing.T) {
+	q := generic.New[*int]()
+	one, two := 1, 2
+	q.Push(&one)
+	q.Push(nil)
+	q.Push(&two)
+	q.Push(nil)
+
+	v, ok := q.Pop()
+	if !ok || v != &one {
+		t.Errorf("Expected: %v; Got: %v", &one, v)
+	}
+	v, ok = q.Pop()
+	if !ok || v != nil {
+		t.Errorf("Expected: nil; Got: %v", v)
+	}
+	v, ok = q.Pop()
+	if !ok || v != &two {
+		t.Errorf("Expected: %v; Got: %v", &two, v)
+	}
+	v, ok = q.Pop()
+	if !ok || v != nil {
+		t.Errorf("Expected: nil; Got: %v", v)
+	}
+	_, ok = q.Pop()
+	if ok {
+		t.Error("Expected: empty slice (ok=false); Got: ok=true")
+	}
+}
+
+func TestPopWithStructValuesShouldReturnAllValuesInOrder(t *testing.T) {
+	type item struct {
+		id   int
+		name string
+	}
+	var q generic.Queue[item]
+	for i := 0; i < pushCount; i++ {
+		q.Push(item{id: i, name: "item"})
+	}
+	for i := 0; i < pushCount; i++ {
+		if v, ok := q.Pop(); !ok || v.id != i || v.name != "item" {
+			t.Errorf("Expected: %d; Got: %v", i, v)
+		}
+	}
+	if v, ok := q.Pop(); ok || v != (item{}) {
+		t.Errorf("Expected: zero value (ok=false); Got: %v (ok=%t)", v, ok)
+	}
+}
@@ -0,0 +1,20 @@
+package generic_test
+
+import (
+	"fmt"
+
+	"github.com/ef-ds/queue/generic"
+)
+
+func Example() {
+	var q generic.Queue[int]
+
+	for i := 1; i <= 5; i++ {
+		q.Push(i)
+	}
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		fmt.Print(v)
+	}
+	// Output: 12345
+}
@@ -1,3 +1,3 @@
 module github.com/ef-ds/queue
 
-go 1.11
+go 1.19
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// heapElement is implemented by the values held in a binaryHeap.
+type heapElement[T any] interface {
+	// before reports whether the value must be popped before value o.
+	before(o T) bool
+}
+
+// binaryHeap implements a binary heap, where the value to be popped first is
+// always at index 0. It's used instead of container/heap because it holds the
+// values without boxing them in interfaces, so pushing doesn't allocate.
+// The zero value for binaryHeap is an empty heap ready to use.
+type binaryHeap[T heapElement[T]] []T
+
+// push adds value v to heap h.
+// The complexity is O(log n).
+func (h *binaryHeap[T]) push(v T) {
+	*h = append(*h, v)
+	s := *h
+	for i := len(s) - 1; i > 0; {
+		p := (i - 1) / 2
+		if !s[i].before(s[p]) {
+			break
+		}
+		s[i], s[p] = s[p], s[i]
+		i = p
+	}
+}
+
+// pop removes and returns the first value of heap h, which must not be empty.
+// The complexity is O(log n).
+func (h *binaryHeap[T]) pop() T {
+	s := *h
+	v, n := s[0], len(s)-1
+	s[0] = s[n]
+	var zero T
+	s[n] = zero // Avoid memory leaks
+	s = s[:n]
+	for i := 0; ; {
+		c := 2*i + 1
+		if c >= n {
+			break
+		}
+		if r := c + 1; r < n && s[r].before(s[c]) {
+			c = r
+		}
+		if !s[c].before(s[i]) {
+			break
+		}
+		s[i], s[c] = s[c], s[i]
+		i = c
+	}
+	*h = s
+	return v
+}
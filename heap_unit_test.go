@@ -0,0 +1,45 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBinaryHeapShouldPopValuesInOrder(t *testing.T) {
+	var h binaryHeap[delayEntry]
+	for i := 0; i < pushCount; i++ {
+		// Spread the values over a few due times, out of order.
+		h.push(delayEntry{v: i, due: time.Unix(int64((i*7)%5), 0), seq: uint64(i)})
+	}
+	var last delayEntry
+	for i := 0; len(h) > 0; i++ {
+		e := h.pop()
+		if i > 0 && !last.before(e) {
+			t.Fatalf("Expected: %v before %v", last, e)
+		}
+		last = e
+	}
+	if c := cap(h); c > 0 && h[:c][0].v != nil {
+		t.Error("Expected: popped values to be cleared")
+	}
+}
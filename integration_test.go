@@ -21,6 +21,7 @@
 package queue_test
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/ef-ds/queue"
@@ -182,3 +183,238 @@ func TestPushFrontPopRefillWith0ToPushCountItemsShouldReturnAllValuesInOrder(t *
 		}
 	}
 }
+
+func TestPushFrontPopShouldRetrieveAllElementsInReverseOrder(t *testing.T) {
+	var q queue.Queue
+
+	for i := 0; i < pushCount; i++ {
+		q.PushFront(i)
+	}
+	for i := pushCount - 1; i >= 0; i-- {
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %d", i, v)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+	}
+}
+
+func TestPushPopBackShouldRetrieveAllElementsInReverseOrder(t *testing.T) {
+	var q queue.Queue
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := pushCount - 1; j >= 0; j-- {
+			if v, ok := q.Back(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+			if v, ok := q.PopBack(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		if q.Len() != 0 {
+			t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+		}
+	}
+}
+
+// sizings holds the options used to check the queue works with a variety
+// of slice sizes, besides the default ones.
+var sizings = map[string][]queue.Option{
+	"default": nil,
+	"tiny": {
+		queue.WithFirstSliceSize(1),
+		queue.WithMaxFirstSliceSize(1),
+		queue.WithMaxInternalSliceSize(1),
+	},
+	"small": {
+		queue.WithFirstSliceSize(1),
+		queue.WithSliceGrowthFactor(3),
+		queue.WithMaxFirstSliceSize(10),
+		queue.WithMaxInternalSliceSize(7),
+	},
+	"large": {
+		queue.WithFirstSliceSize(16),
+		queue.WithSliceGrowthFactor(2),
+		queue.WithMaxFirstSliceSize(1024),
+		queue.WithMaxInternalSliceSize(4096),
+	},
+	"allocator": {
+		queue.WithMaxInternalSliceSize(7),
+		queue.WithAllocator(queue.NewAllocator(7)),
+	},
+}
+
+func TestMixedOperationsShouldMatchSliceModel(t *testing.T) {
+	for name, opts := range sizings {
+		t.Run(name, func(t *testing.T) {
+			q, err := queue.NewWithOptions(opts...)
+			if err != nil {
+				t.Fatalf("Expected: nil error; Got: %v", err)
+			}
+			testMixedOperations(t, q)
+		})
+	}
+}
+
+func testMixedOperations(t *testing.T, q *queue.Queue) {
+	var model []int
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < pushCount*refillCount; i++ {
+		switch op := r.Intn(12); {
+		case op < 2:
+			q.Push(i)
+			model = append(model, i)
+		case op < 3:
+			k := r.Intn(maxBatchCount / 10)
+			vs := make([]interface{}, k)
+			for j := range vs {
+				vs[j] = i + j
+				model = append(model, i+j)
+			}
+			q.PushSlice(vs)
+		case op < 6:
+			q.PushFront(i)
+			model = append([]int{i}, model...)
+		case op < 7:
+			k := r.Intn(maxBatchCount / 10)
+			if k > len(model) {
+				k = len(model)
+			}
+			for j, v := range q.PopN(k) {
+				if v.(int) != model[j] {
+					t.Fatalf("Expected: %d; Got: %d", model[j], v)
+				}
+			}
+			model = model[k:]
+		case op == 10:
+			q.Reserve(r.Intn(maxBatchCount))
+		case op == 11:
+			q.Shrink()
+		case op < 8:
+			v, ok := q.Pop()
+			if len(model) == 0 {
+				if ok {
+					t.Fatalf("Expected: empty queue; Got: %d", v)
+				}
+				continue
+			}
+			if !ok || v.(int) != model[0] {
+				t.Fatalf("Expected: %d; Got: %d", model[0], v)
+			}
+			model = model[1:]
+		default:
+			v, ok := q.PopBack()
+			if len(model) == 0 {
+				if ok {
+					t.Fatalf("Expected: empty queue; Got: %d", v)
+				}
+				continue
+			}
+			if !ok || v.(int) != model[len(model)-1] {
+				t.Fatalf("Expected: %d; Got: %d", model[len(model)-1], v)
+			}
+			model = model[:len(model)-1]
+		}
+
+		if q.Len() != len(model) {
+			t.Fatalf("Expected: %d; Got: %d", len(model), q.Len())
+		}
+		if len(model) > 0 {
+			if j := r.Intn(len(model)); q.At(j).(int) != model[j] {
+				t.Fatalf("Expected: %d; Got: %d", model[j], q.At(j))
+			}
+			if v, ok := q.Front(); !ok || v.(int) != model[0] {
+				t.Fatalf("Expected: %d; Got: %d", model[0], v)
+			}
+			if v, ok := q.Back(); !ok || v.(int) != model[len(model)-1] {
+				t.Fatalf("Expected: %d; Got: %d", model[len(model)-1], v)
+			}
+		}
+	}
+}
+
+func TestAtShouldRetrieveAllElementsInOrder(t *testing.T) {
+	var q queue.Queue
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+			q.PushFront(-j - 1)
+		}
+		for j := 0; j < q.Len(); j++ {
+			want := j - pushCount
+			if v := q.At(j); v.(int) != want {
+				t.Errorf("Expected: %d; Got: %d", want, v)
+			}
+		}
+		for j := 0; j < pushCount; j++ {
+			q.Pop()
+			q.PopBack()
+		}
+	}
+}
+
+func TestSwapShouldReverseAllElements(t *testing.T) {
+	var q queue.Queue
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+	for i, j := 0, q.Len()-1; i < j; i, j = i+1, j-1 {
+		q.Swap(i, j)
+	}
+	for i := pushCount - 1; i >= 0; i-- {
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %d", i, v)
+		}
+	}
+}
+
+func TestPushSlicePopNShouldRetrieveAllElementsInOrder(t *testing.T) {
+	var q queue.Queue
+	vs := make([]interface{}, pushCount)
+	for i := range vs {
+		vs[i] = i
+	}
+
+	for i := 0; i < refillCount; i++ {
+		q.PushSlice(vs)
+		q.Push(pushCount)
+		popped := q.PopN(pushCount + 1)
+		if len(popped) != pushCount+1 {
+			t.Fatalf("Expected: %d; Got: %d", pushCount+1, len(popped))
+		}
+		for j, v := range popped {
+			if v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		if q.Len() != 0 {
+			t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+		}
+	}
+}
+
+func TestResetRefillQueueShouldRetrieveAllElementsInOrder(t *testing.T) {
+	var q queue.Queue
+	q.Reserve(pushCount)
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < pushCount/2; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		q.Reset()
+		if q.Len() != 0 {
+			t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "fmt"
+
+// checkInvariants checks all the invariant conditions in d that we can think of,
+// returning an error describing the first violated condition, if any.
+// The complexity is O(n).
+func (d *Queue) checkInvariants() error {
+	fail := func(what string, got, want interface{}) error {
+		return fmt.Errorf("invariant fail: %s; got %v want %v", what, got, want)
+	}
+	if d.tail == nil {
+		// Zero value.
+		if d.head != nil {
+			return fail("nil head when zero", d.head, nil)
+		}
+		if d.len != 0 {
+			return fail("zero length when zero", d.len, 0)
+		}
+		return nil
+	}
+	if d.hlp != len(d.head.v)-1 {
+		return fail("hlp pointing to the last head index", d.hlp, len(d.head.v)-1)
+	}
+	if d.hp < 0 || d.hp > d.hlp {
+		return fail("hp within head slice", d.hp, d.hlp)
+	}
+	if d.tp < 0 || d.tp > len(d.tail.v) {
+		return fail("tp within tail slice", d.tp, len(d.tail.v))
+	}
+	if d.len == 0 && (d.head != d.tail || d.hp != d.tp) {
+		return fail("head and tail at the same position when empty", d.tp, d.hp)
+	}
+
+	// Check the ring links and count the nodes.
+	size := 0
+	for n := d.head; ; {
+		if n.n.p != n || n.p.n != n {
+			return fail("consistent next and previous links", n.n.p, n)
+		}
+		size++
+		n = n.n
+		if n == d.head {
+			break
+		}
+	}
+
+	// Count the values and the nodes in use from head to tail.
+	count, used := 0, 0
+	for n, start := d.head, d.hp; ; n, start = n.n, 0 {
+		used++
+		end := len(n.v)
+		if n == d.tail {
+			end = d.tp
+		}
+		count += end - start
+		if n == d.tail {
+			break
+		}
+	}
+	if count != d.len {
+		return fail("length matching the number of values", count, d.len)
+	}
+	if d.spare != size-used {
+		return fail("spare matching the number of spare nodes", d.spare, size-used)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.23
+
+package queue
+
+import "iter"
+
+// All returns an iterator over the index-value pairs of queue d, from the
+// front to the back of the queue. The iterator walks the internal slices
+// directly and doesn't remove any elements.
+// Elements can be replaced with Set during the iteration, but the iterator
+// panics if the queue is modified by any other operation before it's done.
+func (d *Queue) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		if d.len == 0 {
+			return
+		}
+		head, hp, tail, tp, l := d.head, d.hp, d.tail, d.tp, d.len
+		i := 0
+		for n, p := head, hp; ; n, p = n.n, 0 {
+			end := len(n.v)
+			if n == tail {
+				end = tp
+			}
+			for ; p < end; p++ {
+				// Only the read is checked, leaving the loop body free to
+				// use the queue.
+				if debug {
+					d.debugEnterRead("All")
+				}
+				v := n.v[p]
+				if debug {
+					d.debugExitRead()
+				}
+				if !yield(i, v) {
+					return
+				}
+				if d.head != head || d.hp != hp || d.tail != tail || d.tp != tp || d.len != l {
+					panic("queue: queue modified during iteration")
+				}
+				i++
+			}
+			if n == tail {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of queue d, from the front to the
+// back of the queue. Values has the same semantics as All.
+func (d *Queue) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, v := range d.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator that removes and yields the elements of queue d,
+// from the front to the back of the queue, until the queue is empty.
+// Each element is removed before it's yielded, so stopping the iteration
+// early leaves only the not yet yielded elements in the queue, and the loop
+// body is free to push new elements, which are then yielded as well.
+// The iterator walks the internal slices directly, removing each element in
+// place without the overhead of calling Pop for it.
+func (d *Queue) Drain() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for d.len > 0 {
+			n, end := d.head, d.hlp+1
+			if n == d.tail {
+				end = d.tp
+			}
+			for p := d.hp; p < end; p++ {
+				// The element is removed before it's yielded, so check only
+				// the removal, leaving the loop body free to use the queue.
+				if debug {
+					d.debugEnter("Drain")
+				}
+				v := n.v[p]
+				n.v[p] = nil // Avoid memory leaks
+				d.len--
+				if p+1 < end {
+					d.hp = p + 1
+				} else {
+					// End of the run, so move on to the next slice, if any.
+					d.advanceHead(end)
+					if d.trim != nil {
+						d.trimSpare()
+					}
+				}
+				if debug {
+					d.debugExit()
+				}
+
+				head, hp, tail, tp, l := d.head, d.hp, d.tail, d.tp, d.len
+				if !yield(v) {
+					return
+				}
+				if d.head != head || d.hp != hp || d.tail != tail || d.tp != tp || d.len != l {
+					// The loop body modified the queue, so start a new run
+					// from the current head.
+					break
+				}
+			}
+		}
+	}
+}
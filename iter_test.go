@@ -0,0 +1,214 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.23
+
+package queue_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestIteratorsWithZeroValueShouldYieldNothing(t *testing.T) {
+	var q queue.Queue
+
+	for i, v := range q.All() {
+		t.Errorf("Expected: no values; Got: %d, %v", i, v)
+	}
+	for v := range q.Values() {
+		t.Errorf("Expected: no values; Got: %v", v)
+	}
+	for v := range q.Drain() {
+		t.Errorf("Expected: no values; Got: %v", v)
+	}
+}
+
+func TestAllShouldStopWhenBreakingTheLoop(t *testing.T) {
+	var q queue.Queue
+	for i := 0; i < 10; i++ {
+		q.Push(i)
+	}
+
+	count := 0
+	for i, v := range q.All() {
+		if v.(int) != i {
+			t.Errorf("Expected: %d; Got: %d", i, v)
+		}
+		count++
+		if i == 4 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Errorf("Expected: 5; Got: %d", count)
+	}
+	for v := range q.Values() {
+		if v.(int) == 4 {
+			break
+		}
+	}
+	if q.Len() != 10 {
+		t.Errorf("Expected: 10; Got: %d", q.Len())
+	}
+}
+
+func TestAllShouldAllowSetDuringIteration(t *testing.T) {
+	var q queue.Queue
+	for i := 0; i < 10; i++ {
+		q.Push(i)
+	}
+
+	for i, v := range q.All() {
+		q.Set(i, v.(int)*2)
+	}
+	for i := 0; i < 10; i++ {
+		if v, _ := q.Pop(); v.(int) != i*2 {
+			t.Errorf("Expected: %d; Got: %d", i*2, v)
+		}
+	}
+}
+
+func TestValuesShouldPanicWhenModifiedDuringIteration(t *testing.T) {
+	tests := map[string]func(q *queue.Queue){
+		"Push":      func(q *queue.Queue) { q.Push(1) },
+		"PushFront": func(q *queue.Queue) { q.PushFront(1) },
+		"Pop":       func(q *queue.Queue) { q.Pop() },
+		"PopBack":   func(q *queue.Queue) { q.PopBack() },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			var q queue.Queue
+			q.Push(1)
+			q.Push(2)
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("Expected: panic; Got: none")
+				}
+			}()
+			for range q.Values() {
+				modify(&q)
+			}
+		})
+	}
+}
+
+func TestDrainShouldYieldValuesPushedDuringIteration(t *testing.T) {
+	var q queue.Queue
+	q.Push(0)
+
+	want := 0
+	for v := range q.Drain() {
+		if v.(int) != want {
+			t.Errorf("Expected: %d; Got: %d", want, v)
+		}
+		want++
+		if v.(int) < 9 {
+			q.Push(v.(int) + 1)
+		}
+	}
+	if want != 10 {
+		t.Errorf("Expected: 10; Got: %d", want)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected: 0; Got: %d", q.Len())
+	}
+}
+
+func TestDrainShouldKeepRemainingValuesWhenBreakingTheLoop(t *testing.T) {
+	var q queue.Queue
+	for i := 0; i < 10; i++ {
+		q.Push(i)
+	}
+
+	for v := range q.Drain() {
+		if v.(int) == 3 {
+			break
+		}
+	}
+	if q.Len() != 6 {
+		t.Errorf("Expected: 6; Got: %d", q.Len())
+	}
+	if v, _ := q.Front(); v.(int) != 4 {
+		t.Errorf("Expected: 4; Got: %d", v)
+	}
+}
+
+func TestAllShouldRetrieveAllElementsInOrder(t *testing.T) {
+	var q queue.Queue
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+			q.PushFront(-j - 1)
+		}
+		count := 0
+		for j, v := range q.All() {
+			if want := j - pushCount; v.(int) != want {
+				t.Errorf("Expected: %d; Got: %d", want, v)
+			}
+			count++
+		}
+		if count != pushCount*2 {
+			t.Errorf("Expected: %d; Got: %d", pushCount*2, count)
+		}
+		want := -pushCount
+		for v := range q.Drain() {
+			if v.(int) != want {
+				t.Errorf("Expected: %d; Got: %d", want, v)
+			}
+			want++
+		}
+		if q.Len() != 0 {
+			t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+		}
+	}
+}
+
+func ExampleQueue_Drain() {
+	var q queue.Queue
+
+	for i := 1; i <= 5; i++ {
+		q.Push(i)
+	}
+	for v := range q.Drain() {
+		fmt.Print(v)
+	}
+	fmt.Print(" ", q.Len())
+	// Output: 12345 0
+}
+
+func ExampleQueue_All() {
+	var q queue.Queue
+
+	for i := 1; i <= 3; i++ {
+		q.Push(i * 10)
+	}
+	for i, v := range q.All() {
+		fmt.Println(i, v)
+	}
+	// Output:
+	// 0 10
+	// 1 20
+	// 2 30
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.23
+
+package queue
+
+import "testing"
+
+func TestDrainShouldKeepInvariants(t *testing.T) {
+	tests := map[string]*TrimPolicy{
+		"no trim policy": nil,
+		"trim policy":    {MaxSpareNodes: 1},
+	}
+	for name, trim := range tests {
+		t.Run(name, func(t *testing.T) {
+			var q Queue
+			q.SetTrimPolicy(trim)
+			next, want := 0, 0
+			for i := 0; i < refillCount; i++ {
+				for j := 0; j < pushCount; j++ {
+					q.Push(next)
+					next++
+				}
+				count := 0
+				for v := range q.Drain() {
+					if v.(int) != want {
+						t.Fatalf("Expected: %d; Got: %d", want, v)
+					}
+					want++
+					assertInvariants(t, &q, func(i int) interface{} { return want + i })
+					count++
+					if count%7 == 0 {
+						// Push in the middle of the runs, restarting them.
+						q.Push(next)
+						next++
+					}
+					if count == pushCount {
+						// Stop early, keeping the remaining values for the
+						// next round.
+						break
+					}
+				}
+			}
+			for range q.Drain() {
+				want++
+			}
+			if want != next {
+				t.Errorf("Expected: %d; Got: %d", next, want)
+			}
+			assertInvariants(t, &q, nil)
+		})
+	}
+}
+
+func TestAllShouldPanicOnConcurrentWriteWithDebugTag(t *testing.T) {
+	if !debug {
+		t.Skip("requires the queuedebug build tag")
+	}
+	var q Queue
+	q.Push(1)
+
+	// Simulate a Pop in progress in another goroutine.
+	q.debugEnter("Pop")
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected: panic; Got: none")
+			}
+		}()
+		for range q.All() {
+		}
+	}()
+	q.debugExit()
+
+	// Reads in the loop body are allowed.
+	for _, v := range q.All() {
+		if f, _ := q.Front(); f != v {
+			t.Errorf("Expected: %v; Got: %v", v, f)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// keyedQueue implements the storage shared by the queues that identify their
+// values by key, holding at most one pending value per key.
+// The pending entries are held in a Queue, so their positions never change,
+// and in a map by key, allowing them to be found, updated in place and
+// removed in O(1). Removed entries are only marked as removed and left in the
+// queue, to be discarded once they reach its front, or once they outnumber the
+// pending entries, when the queue is compacted.
+type keyedQueue struct {
+	// q holds the entries, including the removed ones, in push order.
+	q Queue
+
+	// pending maps the key of each pending value to its entry.
+	pending map[interface{}]*keyedEntry
+
+	// removed holds the number of removed entries in q.
+	removed int
+
+	// key returns the key of a value.
+	key func(v interface{}) interface{}
+}
+
+// keyedEntry represents a keyedQueue value.
+type keyedEntry struct {
+	// key holds the key of v.
+	key interface{}
+
+	// v holds the user added value.
+	v interface{}
+
+	// removed indicates whether the entry was removed.
+	removed bool
+}
+
+// newKeyedQueue returns an initialized keyed queue using key to get the key of
+// its values. newKeyedQueue panics if key is nil.
+func newKeyedQueue(key func(v interface{}) interface{}) keyedQueue {
+	if key == nil {
+		panic("queue: nil key function")
+	}
+	return keyedQueue{pending: make(map[interface{}]*keyedEntry), key: key}
+}
+
+// len returns the number of pending values.
+func (d *keyedQueue) len() int { return len(d.pending) }
+
+// contains returns whether there's a pending value with key.
+func (d *keyedQueue) contains(key interface{}) bool {
+	_, ok := d.pending[key]
+	return ok
+}
+
+// push adds value v to the back of the queue if there's no pending value with
+// the same key, returning its entry. If there is, the pending value entry is
+// returned instead and the queue is left unchanged.
+// The second, bool result indicates whether v was added.
+func (d *keyedQueue) push(v interface{}) (*keyedEntry, bool) {
+	k := d.key(v)
+	if e, ok := d.pending[k]; ok {
+		return e, false
+	}
+	e := &keyedEntry{key: k, v: v}
+	d.pending[k] = e
+	d.q.Push(e)
+	return e, true
+}
+
+// front returns the first pending value, discarding the removed entries
+// before it.
+func (d *keyedQueue) front() (interface{}, bool) {
+	for {
+		v, ok := d.q.Front()
+		if !ok {
+			return nil, false
+		}
+		if e := v.(*keyedEntry); !e.removed {
+			return e.v, true
+		}
+		d.q.Pop()
+		d.removed--
+	}
+}
+
+// pop retrieves and removes the first pending value, discarding the removed
+// entries before it.
+func (d *keyedQueue) pop() (interface{}, bool) {
+	for {
+		v, ok := d.q.Pop()
+		if !ok {
+			return nil, false
+		}
+		if e := v.(*keyedEntry); !e.removed {
+			delete(d.pending, e.key)
+			return e.v, true
+		}
+		d.removed--
+	}
+}
+
+// remove removes the pending value with key, if any.
+// The result indicates whether a value was removed.
+func (d *keyedQueue) remove(key interface{}) bool {
+	e, ok := d.pending[key]
+	if !ok {
+		return false
+	}
+	delete(d.pending, key)
+	e.removed = true
+	e.v = nil // Avoid memory leaks
+	d.removed++
+	if d.removed > len(d.pending) {
+		d.compact()
+	}
+	return true
+}
+
+// compact discards all the removed entries, keeping the pending ones in order.
+// As it's only called when most entries are removed, its cost is amortized
+// over the removals, keeping them O(1).
+func (d *keyedQueue) compact() {
+	for n := d.q.Len(); n > 0; n-- {
+		v, _ := d.q.Pop()
+		if !v.(*keyedEntry).removed {
+			d.q.Push(v)
+		}
+	}
+	d.removed = 0
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "sync/atomic"
+
+// cacheLinePad is used to keep the fields updated by different goroutines in
+// different cache lines, avoiding false sharing.
+type cacheLinePad [64]byte
+
+// MPMCQueue implements a lock-free, unbounded First-In-First-Out (FIFO) queue
+// that is safe for concurrent use by multiple producers and multiple consumers.
+//
+// Just like Queue, MPMCQueue stores its values in a linked list of arrays:
+// each segment holds maxInternalSliceSize slots, which producers and consumers
+// claim by atomically incrementing the segment enqueue and dequeue indexes.
+// Producers that find the tail segment full link a new one, so no goroutine
+// ever blocks on another: a stalled goroutine can't prevent the others from
+// making progress.
+//
+// Unlike Queue, segments are not reused in a ring, as it's not possible to know
+// when all goroutines are done with a segment without extra synchronization.
+// Consumed segments are left to the garbage collector instead.
+//
+// MPMCQueue must be created with NewMPMC.
+type MPMCQueue struct {
+	_ cacheLinePad
+
+	// head points to the segment values are currently popped from.
+	head atomic.Pointer[segment]
+
+	_ cacheLinePad
+
+	// tail points to the segment values are currently pushed to.
+	tail atomic.Pointer[segment]
+
+	_ cacheLinePad
+}
+
+// segment represents a MPMCQueue segment.
+type segment struct {
+	// deq holds the index of the next slot to be claimed by a consumer.
+	// Once deq reaches the number of slots, the segment is fully consumed.
+	deq atomic.Int64
+
+	_ cacheLinePad
+
+	// enq holds the index of the next slot to be claimed by a producer.
+	// Once enq reaches the number of slots, the segment is full.
+	enq atomic.Int64
+
+	_ cacheLinePad
+
+	// n points to the next segment in the linked list.
+	n atomic.Pointer[segment]
+
+	// v holds the segment slots.
+	v [maxInternalSliceSize]slot
+}
+
+// slot holds a value pushed into a MPMCQueue.
+type slot struct {
+	// v holds the slot value.
+	v interface{}
+
+	// s holds the slot state. The value is only read after s changes from
+	// slotEmpty to slotReady, which publishes the value to the consumer.
+	s atomic.Uint32
+}
+
+// Slot states.
+const (
+	// slotEmpty marks a slot not written yet.
+	slotEmpty uint32 = iota
+
+	// slotReady marks a slot holding a value ready to be consumed.
+	slotReady
+
+	// slotTaken marks a slot already claimed by a consumer.
+	slotTaken
+)
+
+// NewMPMC returns an initialized lock-free multi-producer multi-consumer queue.
+func NewMPMC() *MPMCQueue {
+	d := new(MPMCQueue)
+	s := new(segment)
+	d.head.Store(s)
+	d.tail.Store(s)
+	return d
+}
+
+// Push adds value v to the the back of the queue.
+// The complexity is O(1).
+func (d *MPMCQueue) Push(v interface{}) {
+	for {
+		t := d.tail.Load()
+		i := t.enq.Add(1) - 1
+		if i < maxInternalSliceSize {
+			s := &t.v[i]
+			s.v = v
+			if s.s.CompareAndSwap(slotEmpty, slotReady) {
+				return
+			}
+			// A consumer found the slot empty and marked it as taken,
+			// so try again with the next slot.
+			s.v = nil
+			continue
+		}
+
+		// The tail segment is full, so link a new one holding v or, if another
+		// producer already did it, help it move the tail forward.
+		if t != d.tail.Load() {
+			continue
+		}
+		if n := t.n.Load(); n != nil {
+			d.tail.CompareAndSwap(t, n)
+			continue
+		}
+		n := new(segment)
+		n.v[0].v = v
+		n.v[0].s.Store(slotReady)
+		n.enq.Store(1)
+		if t.n.CompareAndSwap(nil, n) {
+			d.tail.CompareAndSwap(t, n)
+			return
+		}
+	}
+}
+
+// Pop retrieves and removes the current element from the front of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *MPMCQueue) Pop() (interface{}, bool) {
+	for {
+		h := d.head.Load()
+		if h.deq.Load() >= h.enq.Load() && h.n.Load() == nil {
+			return nil, false
+		}
+		i := h.deq.Add(1) - 1
+		if i >= maxInternalSliceSize {
+			// The head segment is fully consumed, so move on to the next one.
+			n := h.n.Load()
+			if n == nil {
+				return nil, false
+			}
+			d.head.CompareAndSwap(h, n)
+			continue
+		}
+		s := &h.v[i]
+		if s.s.Swap(slotTaken) == slotEmpty {
+			// The producer that claimed the slot hasn't written it yet, so the
+			// slot is now marked as taken and the producer will try again.
+			continue
+		}
+		v := s.v
+		s.v = nil // Avoid memory leaks
+		return v, true
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestMPMCWhenEmptyShouldReturnAsEmpty(t *testing.T) {
+	q := queue.NewMPMC()
+
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	q.Push(nil)
+	if v, ok := q.Pop(); !ok || v != nil {
+		t.Errorf("Expected: nil; Got: %v", v)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+}
+
+func TestMPMCRefillShouldRetrieveAllElementsInOrder(t *testing.T) {
+	q := queue.NewMPMC()
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < pushCount; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		if _, ok := q.Pop(); ok {
+			t.Error("Expected: false as the queue is empty; Got: true")
+		}
+	}
+}
+
+func TestMPMCWithConcurrentProducersAndConsumersShouldDeliverAllValues(t *testing.T) {
+	q := queue.NewMPMC()
+	const producers, consumers = 8, 8
+	count := pushCount * refillCount
+
+	var pwg, cwg sync.WaitGroup
+	var done atomic.Bool
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < count; i++ {
+				q.Push(p*count + i)
+			}
+		}(p)
+	}
+
+	seen := make([][]int, consumers)
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, ok := q.Pop()
+				if !ok {
+					if done.Load() {
+						// Producers are done, so one last pop makes sure
+						// no values were left behind.
+						if v, ok = q.Pop(); !ok {
+							return
+						}
+					} else {
+						continue
+					}
+				}
+				seen[c] = append(seen[c], v.(int))
+			}
+		}(c)
+	}
+	pwg.Wait()
+	done.Store(true)
+	cwg.Wait()
+
+	received := make([]int, producers*count)
+	for _, vs := range seen {
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range vs {
+			received[v]++
+			// Values from the same producer are always received in order.
+			p, i := v/count, v%count
+			if i <= last[p] {
+				t.Fatalf("Expected: value after %d; Got: %d", last[p], i)
+			}
+			last[p] = i
+		}
+	}
+	for v, c := range received {
+		if c != 1 {
+			t.Fatalf("Expected: value %d received once; Got: %d times", v, c)
+		}
+	}
+}
+
+func BenchmarkMPMC(b *testing.B) {
+	q := queue.NewMPMC()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Push(nil)
+			q.Pop()
+		}
+	})
+}
+
+func BenchmarkMutexQueue(b *testing.B) {
+	q := queue.NewBlocking()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Push(nil)
+			q.TryPop()
+		}
+	})
+}
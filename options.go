@@ -0,0 +1,129 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "errors"
+
+// config holds the slice sizing of a queue.
+// Refer to the package constants for the meaning of each field.
+type config struct {
+	firstSliceSize       int
+	sliceGrowthFactor    int
+	maxFirstSliceSize    int
+	maxInternalSliceSize int
+
+	// alloc holds the allocator the internal nodes are taken from, if any.
+	alloc *Allocator
+}
+
+// defaultConfig holds the sizing used by the zero value queue and New.
+var defaultConfig = config{
+	firstSliceSize:       firstSliceSize,
+	sliceGrowthFactor:    sliceGrowthFactor,
+	maxFirstSliceSize:    maxFirstSliceSize,
+	maxInternalSliceSize: maxInternalSliceSize,
+}
+
+// Option configures a queue created by NewWithOptions.
+type Option func(c *config)
+
+// WithFirstSliceSize sets the size of the first slice created by the queue.
+// The default is 4. The size is capped at the max first slice size.
+func WithFirstSliceSize(size int) Option {
+	return func(c *config) { c.firstSliceSize = size }
+}
+
+// WithSliceGrowthFactor sets by how much the first slice grows each time it
+// gets full, until it reaches the max first slice size. The default is 4.
+func WithSliceGrowthFactor(factor int) Option {
+	return func(c *config) { c.sliceGrowthFactor = factor }
+}
+
+// WithMaxFirstSliceSize sets the size up to which the first slice grows.
+// The default is 64. The size is capped at the max internal slice size, so
+// setting a max internal slice size smaller than 64 also limits the first slice.
+func WithMaxFirstSliceSize(size int) Option {
+	return func(c *config) { c.maxFirstSliceSize = size }
+}
+
+// WithMaxInternalSliceSize sets the size of all slices created after the first
+// one. The default is 256.
+func WithMaxInternalSliceSize(size int) Option {
+	return func(c *config) { c.maxInternalSliceSize = size }
+}
+
+// WithAllocator sets the allocator the queue takes its internal nodes from and
+// returns them to. The allocator slice size must match the max internal slice
+// size of the queue.
+func WithAllocator(a *Allocator) Option {
+	return func(c *config) { c.alloc = a }
+}
+
+// NewWithOptions returns an initialized queue configured by opts. Any sizing
+// not set by opts uses the same default as the zero value queue.
+// An error is returned if the resulting sizing is invalid.
+func NewWithOptions(opts ...Option) (*Queue, error) {
+	c := defaultConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.firstSliceSize < 1:
+		return nil, errors.New("queue: first slice size must be greater than zero")
+	case c.sliceGrowthFactor < 2:
+		return nil, errors.New("queue: slice growth factor must be greater than one")
+	case c.maxFirstSliceSize < c.firstSliceSize:
+		return nil, errors.New("queue: max first slice size must not be less than the first slice size")
+	case c.maxInternalSliceSize < 1:
+		return nil, errors.New("queue: max internal slice size must be greater than zero")
+	case c.alloc != nil && c.alloc.size != c.maxInternalSliceSize:
+		return nil, errors.New("queue: allocator slice size must match the max internal slice size")
+	}
+
+	// Only the first slice is meant to grow, so it must never be allowed to
+	// grow beyond the internal slices size; otherwise, Push would grow the
+	// internal slices as well.
+	if c.maxFirstSliceSize > c.maxInternalSliceSize {
+		c.maxFirstSliceSize = c.maxInternalSliceSize
+	}
+	if c.firstSliceSize > c.maxFirstSliceSize {
+		c.firstSliceSize = c.maxFirstSliceSize
+	}
+	return &Queue{cfg: &c}, nil
+}
+
+// grow returns the size the first slice grows to from size, which is never
+// larger than the max first slice size.
+func (c *config) grow(size int) int {
+	size *= c.sliceGrowthFactor
+	if size > c.maxFirstSliceSize {
+		size = c.maxFirstSliceSize
+	}
+	return size
+}
+
+// config returns the slice sizing of queue d.
+func (d *Queue) config() *config {
+	if d.cfg == nil {
+		return &defaultConfig
+	}
+	return d.cfg
+}
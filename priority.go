@@ -0,0 +1,130 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// PriorityQueue implements a priority queue that is stable: values with higher
+// priorities are popped first, and values with the same priority are popped
+// in the order they were pushed (FIFO), just like a Queue.
+//
+// Each distinct priority level with values owns a Queue, so pushing to and
+// popping from an existing level is O(1). A heap of the non-empty levels
+// selects the highest one, so only adding a level to and removing a level
+// from the heap is O(log l), where l is the number of non-empty levels.
+// Emptied levels keep their Queue, so refilling them reuses its nodes, up to
+// maxIdlePriorityLevels emptied levels; the queues of other emptied levels
+// are discarded.
+// The zero value for PriorityQueue is an empty queue ready to use.
+type PriorityQueue struct {
+	// levels maps each priority level to the queue holding its values.
+	// The map holds all the non-empty levels and up to maxIdlePriorityLevels
+	// empty ones.
+	levels map[int]*Queue
+
+	// index holds the non-empty priority levels, highest first.
+	index binaryHeap[priorityLevel]
+
+	// idle holds the number of empty levels in levels.
+	idle int
+
+	// len holds the current queue values length.
+	len int
+}
+
+// maxIdlePriorityLevels is the maximum number of empty priority levels a
+// PriorityQueue keeps the queue of for reuse.
+const maxIdlePriorityLevels = 16
+
+// priorityLevel is a priority level in the index of a PriorityQueue.
+type priorityLevel int
+
+// before reports whether level l must be popped before level o, which is the
+// case when l is higher.
+func (l priorityLevel) before(o priorityLevel) bool { return l > o }
+
+// NewPriority returns an initialized priority queue.
+func NewPriority() *PriorityQueue {
+	return new(PriorityQueue)
+}
+
+// Len returns the number of elements of queue d.
+// The complexity is O(1).
+func (d *PriorityQueue) Len() int { return d.len }
+
+// Front returns the first element of the highest priority level of queue d or
+// nil if the queue is empty, alongside its priority.
+// The third, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *PriorityQueue) Front() (interface{}, int, bool) {
+	if d.len == 0 {
+		return nil, 0, false
+	}
+	p := int(d.index[0])
+	v, _ := d.levels[p].Front()
+	return v, p, true
+}
+
+// Push adds value v with priority to the back of its priority level.
+// The complexity is O(1) if the priority level already has values,
+// O(log l) otherwise, where l is the number of non-empty priority levels.
+func (d *PriorityQueue) Push(v interface{}, priority int) {
+	q, ok := d.levels[priority]
+	switch {
+	case !ok:
+		if d.levels == nil {
+			d.levels = make(map[int]*Queue)
+		}
+		q = new(Queue)
+		d.levels[priority] = q
+		d.index.push(priorityLevel(priority))
+	case q.Len() == 0:
+		// An idle level, so reuse its queue.
+		d.idle--
+		d.index.push(priorityLevel(priority))
+	}
+	q.Push(v)
+	d.len++
+}
+
+// Pop retrieves and removes the current element from the front of the highest
+// priority level, alongside its priority.
+// The third, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1) if the priority level has more values,
+// O(log l) otherwise, where l is the number of non-empty priority levels.
+func (d *PriorityQueue) Pop() (interface{}, int, bool) {
+	if d.len == 0 {
+		return nil, 0, false
+	}
+	p := int(d.index[0])
+	q := d.levels[p]
+	v, _ := q.Pop()
+	if q.Len() == 0 {
+		d.index.pop()
+		if d.idle < maxIdlePriorityLevels {
+			d.idle++
+		} else {
+			delete(d.levels, p)
+		}
+	}
+	d.len--
+	return v, p, true
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"container/heap"
+	"strconv"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestPriorityQueueWithZeroValueShouldReturnReadyToUseQueue(t *testing.T) {
+	var q queue.PriorityQueue
+
+	if _, _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	q.Push(1, 0)
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+	if v, p, ok := q.Pop(); !ok || v.(int) != 1 || p != 0 {
+		t.Errorf("Expected: 1, 0; Got: %v, %d", v, p)
+	}
+}
+
+func TestPriorityQueueShouldPopHighestPriorityFirstAndKeepFIFOWithinPriority(t *testing.T) {
+	const levels = 5
+	q := queue.NewPriority()
+	for i := 0; i < levels*pushCount; i++ {
+		q.Push(i, i%levels-2) // Negative priorities are valid as well.
+	}
+
+	for p := levels - 3; p >= -2; p-- {
+		last := -1
+		for i := 0; i < pushCount; i++ {
+			fv, fp, _ := q.Front()
+			v, vp, ok := q.Pop()
+			if !ok || v != fv || vp != fp {
+				t.Fatalf("Expected: Front and Pop to match; Got: %v, %d and %v, %d", fv, fp, v, vp)
+			}
+			if vp != p {
+				t.Fatalf("Expected: priority %d; Got: %d", p, vp)
+			}
+			if v.(int) <= last {
+				t.Fatalf("Expected: value after %d; Got: %d", last, v)
+			}
+			last = v.(int)
+		}
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0; Got: %d", l)
+	}
+}
+
+func TestPriorityQueueShouldHandleLevelsEmptiedAndRefilled(t *testing.T) {
+	q := queue.NewPriority()
+	q.Push("low", 1)
+	q.Push("high", 2)
+	if v, _, _ := q.Pop(); v != "high" {
+		t.Errorf("Expected: high; Got: %v", v)
+	}
+	q.Push("high again", 2)
+	q.Push("highest", 3)
+
+	for _, want := range []string{"highest", "high again", "low"} {
+		if v, _, _ := q.Pop(); v != want {
+			t.Errorf("Expected: %s; Got: %v", want, v)
+		}
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0; Got: %d", l)
+	}
+}
+
+// heapQueue implements a stable priority queue using container/heap,
+// used as a baseline in the benchmarks.
+type heapQueue struct {
+	items []heapItem
+	seq   uint64
+}
+
+type heapItem struct {
+	v        interface{}
+	priority int
+	seq      uint64
+}
+
+func (h *heapQueue) Len() int { return len(h.items) }
+func (h *heapQueue) Less(i, j int) bool {
+	if h.items[i].priority != h.items[j].priority {
+		return h.items[i].priority > h.items[j].priority
+	}
+	return h.items[i].seq < h.items[j].seq
+}
+func (h *heapQueue) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *heapQueue) Push(x interface{}) { h.items = append(h.items, x.(heapItem)) }
+func (h *heapQueue) Pop() interface{} {
+	x := h.items[len(h.items)-1]
+	h.items[len(h.items)-1] = heapItem{} // Avoid memory leaks
+	h.items = h.items[:len(h.items)-1]
+	return x
+}
+
+var priorityLevels = []int{1, 8, 64}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	for _, levels := range priorityLevels {
+		b.Run(strconv.Itoa(levels), func(b *testing.B) {
+			q := queue.NewPriority()
+			for n := 0; n < b.N; n++ {
+				for i := 0; i < fillCount; i++ {
+					q.Push(nil, i%levels)
+				}
+				for q.Len() > 0 {
+					tmp, _, tmp2 = q.Pop()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPriorityHeap(b *testing.B) {
+	for _, levels := range priorityLevels {
+		b.Run(strconv.Itoa(levels), func(b *testing.B) {
+			q := new(heapQueue)
+			for n := 0; n < b.N; n++ {
+				for i := 0; i < fillCount; i++ {
+					heap.Push(q, heapItem{priority: i % levels, seq: q.seq})
+					q.seq++
+				}
+				for q.Len() > 0 {
+					tmp = heap.Pop(q).(heapItem).v
+				}
+			}
+		})
+	}
+}
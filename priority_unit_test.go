@@ -0,0 +1,49 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "testing"
+
+func TestPriorityQueueShouldReuseIdleLevelsUpToLimit(t *testing.T) {
+	const levels = maxIdlePriorityLevels + 4
+	var q PriorityQueue
+	q.Push(0, 0)
+	first := q.levels[0]
+	q.Pop()
+	q.Push(1, 0)
+	if q.levels[0] != first {
+		t.Error("Expected: the emptied level queue to be reused; Got: a new queue")
+	}
+	q.Pop()
+
+	for i := 0; i < levels; i++ {
+		q.Push(i, i)
+	}
+	for q.Len() > 0 {
+		q.Pop()
+	}
+	if l := len(q.levels); l != maxIdlePriorityLevels || q.idle != l {
+		t.Errorf("Expected: %d idle levels; Got: %d levels, %d idle", maxIdlePriorityLevels, l, q.idle)
+	}
+	if l := len(q.index); l != 0 {
+		t.Errorf("Expected: 0 levels in the index; Got: %d", l)
+	}
+}
@@ -44,7 +44,7 @@ const (
 	maxInternalSliceSize = 256
 )
 
-// Queue implements an unbounded, dynamically growing double-ended-queue (queue).
+// Queue implements an unbounded, dynamically growing double-ended-queue (deque).
 // The zero value for queue is an empty queue ready to use.
 type Queue struct {
 	// Head points to the first node of the linked list.
@@ -67,6 +67,18 @@ type Queue struct {
 
 	// Len holds the current queue values length.
 	len int
+
+	// spare holds the number of spare nodes in the ring, i.e. the nodes
+	// linked between the tail and head nodes that hold no values.
+	spare int
+
+	// trim holds the policy used to automatically release spare nodes.
+	// A nil trim means spare nodes are never released automatically.
+	trim *TrimPolicy
+
+	// cfg holds the slice sizing set by NewWithOptions.
+	// A nil cfg means the package default sizing is used.
+	cfg *config
 }
 
 // Node represents a queue node.
@@ -77,6 +89,28 @@ type node struct {
 
 	// n points to the next node in the linked list.
 	n *node
+
+	// p points to the previous node in the linked list.
+	p *node
+}
+
+// TrimPolicy determines how many spare nodes a queue keeps in its ring.
+//
+// Popping values from the queue never releases the now empty nodes: they stay
+// linked in the ring as spare nodes, ready to be reused by future pushes.
+// That's what makes the queue so efficient in the stable and refill scenarios,
+// but it also means a queue that once held a large number of values keeps
+// holding the memory needed for all of them. A TrimPolicy allows the queue to
+// release the spare nodes above a given limit as they become empty.
+type TrimPolicy struct {
+	// MaxSpareNodes holds the number of spare nodes the queue is always
+	// allowed to keep.
+	MaxSpareNodes int
+
+	// MaxSparePercent allows the queue to keep spare nodes with a combined
+	// capacity of up to MaxSparePercent percent of its current length.
+	// The queue keeps the larger of the two limits.
+	MaxSparePercent int
 }
 
 // New returns an initialized queue.
@@ -85,11 +119,175 @@ func New() *Queue {
 }
 
 // Init initializes or clears queue d.
+// The trim policy and the options, if any, are kept.
+// If queue d uses an allocator, all its nodes are returned to the allocator.
 func (d *Queue) Init() *Queue {
-	*d = Queue{}
+	if debug {
+		d.debugEnter("Init")
+		defer d.debugExit()
+	}
+	if d.cfg != nil && d.cfg.alloc != nil && d.head != nil {
+		d.reset()
+		d.shrink()
+		d.release(d.head)
+	}
+	*d = Queue{trim: d.trim, cfg: d.cfg}
 	return d
 }
 
+// Reset removes all elements from queue d, but unlike Init, keeps all the
+// internal nodes in the ring for reuse, so refilling the queue doesn't need to
+// allocate them again. Reset still sets all positions that held values to nil,
+// so the removed values can be garbage collected.
+// The trim policy, if any, is applied to the now spare nodes.
+// The complexity is O(Len).
+func (d *Queue) Reset() {
+	if debug {
+		d.debugEnter("Reset")
+		defer d.debugExit()
+	}
+	d.reset()
+}
+
+// reset removes all elements from queue d, keeping its nodes. See Reset.
+func (d *Queue) reset() {
+	if d.head == nil {
+		return
+	}
+	for n, start := d.head, d.hp; ; n, start = n.n, 0 {
+		if n == d.tail {
+			clearValues(n.v[start:d.tp]) // Avoid memory leaks
+			break
+		}
+		clearValues(n.v[start:]) // Avoid memory leaks
+		d.spare++
+	}
+	d.tail = d.head
+	d.hp = 0
+	d.tp = 0
+	d.len = 0
+	if d.trim != nil {
+		d.trimSpare()
+	}
+}
+
+// Reserve makes sure queue d has room to hold n more values without
+// allocating any more memory, adding new spare nodes to the ring if needed.
+// The spare nodes above the trim policy limits, if any, may be released again
+// by later pops.
+// The complexity is O(n/maxInternalSliceSize) plus the number of spare nodes.
+func (d *Queue) Reserve(n int) {
+	if debug {
+		d.debugEnter("Reserve")
+		defer d.debugExit()
+	}
+	if n <= 0 {
+		return
+	}
+	c := d.config()
+	if d.head == nil {
+		// No nodes present yet, so make the first one as large as Push would
+		// eventually grow it to hold n values.
+		size := c.firstSliceSize
+		for size < n && size < c.maxFirstSliceSize {
+			size = c.grow(size)
+		}
+		h := &node{v: make([]interface{}, size)}
+		h.n = h
+		h.p = h
+		d.head = h
+		d.tail = h
+		d.hlp = size - 1
+	}
+
+	// Push grows slices smaller than maxFirstSliceSize when they get full,
+	// so grow the tail slice now if the values won't fit in it.
+	size := len(d.tail.v)
+	for size-d.tp < n && size < c.maxFirstSliceSize {
+		size = c.grow(size)
+	}
+	if size != len(d.tail.v) {
+		nv := make([]interface{}, size)
+		copy(nv, d.tail.v)
+		d.tail.v = nv
+		if d.tail == d.head {
+			d.hlp = size - 1
+		}
+	}
+	room := size - d.tp
+
+	// Count the room in the spare nodes, growing any small ones for the
+	// same reason.
+	for s := d.tail.n; s != d.head && room < n; s = s.n {
+		if len(s.v) < c.maxFirstSliceSize {
+			size := len(s.v)
+			for size < c.maxFirstSliceSize {
+				size = c.grow(size)
+			}
+			s.v = make([]interface{}, size)
+		}
+		room += len(s.v)
+	}
+
+	// Add new spare nodes right before the head until there's enough room.
+	for room < n {
+		s := d.newNode()
+		s.n = d.head
+		s.p = d.head.p
+		d.head.p.n = s
+		d.head.p = s
+		d.spare++
+		room += c.maxInternalSliceSize
+	}
+}
+
+// SetTrimPolicy sets the policy queue d uses to automatically release its
+// spare nodes. If p is nil, spare nodes are never released automatically,
+// which is the default behavior.
+// Any spare nodes above the new policy limits are released right away.
+func (d *Queue) SetTrimPolicy(p *TrimPolicy) {
+	if debug {
+		d.debugEnter("SetTrimPolicy")
+		defer d.debugExit()
+	}
+	if p == nil {
+		d.trim = nil
+		return
+	}
+	t := *p
+	d.trim = &t
+	d.trimSpare()
+}
+
+// Shrink releases all spare nodes of queue d, leaving in the ring only the
+// nodes holding its current values.
+// If queue d uses an allocator, the spare nodes are returned to the allocator.
+// The complexity is O(1), or O(spare nodes) when using an allocator.
+func (d *Queue) Shrink() {
+	if debug {
+		d.debugEnter("Shrink")
+		defer d.debugExit()
+	}
+	d.shrink()
+}
+
+// shrink releases all spare nodes of queue d. See Shrink.
+func (d *Queue) shrink() {
+	if d.spare == 0 {
+		return
+	}
+	if d.cfg != nil && d.cfg.alloc != nil {
+		for n := d.tail.n; n != d.head; {
+			next := n.n
+			d.release(n)
+			n = next
+		}
+	}
+	d.tail.n = d.head
+	d.head.p = d.tail
+	d.spare = 0
+}
+
 // Len returns the number of elements of queue d.
 // The complexity is O(1).
 func (d *Queue) Len() int { return d.len }
@@ -99,48 +297,103 @@ func (d *Queue) Len() int { return d.len }
 // if the queue is empty, false will be returned.
 // The complexity is O(1).
 func (d *Queue) Front() (interface{}, bool) {
+	if debug {
+		d.debugEnterRead("Front")
+		defer d.debugExitRead()
+	}
 	if d.len == 0 {
 		return nil, false
 	}
 	return d.head.v[d.hp], true
 }
 
+// At returns the element at index i of queue d, where index 0 is the front
+// of the queue and index Len()-1 is the back of the queue.
+// At panics if i is out of range.
+// The complexity is O(i/maxInternalSliceSize).
+func (d *Queue) At(i int) interface{} {
+	if debug {
+		d.debugEnterRead("At")
+		defer d.debugExitRead()
+	}
+	return *d.slot(i)
+}
+
+// Set replaces the element at index i of queue d with value v.
+// Set panics if i is out of range.
+// The complexity is O(i/maxInternalSliceSize).
+func (d *Queue) Set(i int, v interface{}) {
+	if debug {
+		d.debugEnter("Set")
+		defer d.debugExit()
+	}
+	*d.slot(i) = v
+}
+
+// Swap swaps the elements at indexes i and j of queue d.
+// Swap panics if i or j are out of range.
+// The complexity is O(max(i, j)/maxInternalSliceSize).
+func (d *Queue) Swap(i, j int) {
+	if debug {
+		d.debugEnter("Swap")
+		defer d.debugExit()
+	}
+	vi, vj := d.slot(i), d.slot(j)
+	*vi, *vj = *vj, *vi
+}
+
 // Push adds value v to the the back of the queue.
 // The complexity is O(1).
 func (d *Queue) Push(v interface{}) {
+	if debug {
+		d.debugEnter("Push")
+		defer d.debugExit()
+	}
+	d.push(v)
+}
+
+// push adds value v to the back of queue d. See Push.
+func (d *Queue) push(v interface{}) {
 	switch {
 	case d.head == nil:
 		// No nodes present yet.
-		h := &node{v: make([]interface{}, firstSliceSize)}
+		size := d.config().firstSliceSize
+		h := &node{v: make([]interface{}, size)}
 		h.n = h
+		h.p = h
 		d.head = h
 		d.tail = h
 		d.tail.v[0] = v
-		d.hlp = firstSliceSize - 1
+		d.hlp = size - 1
 		d.tp = 1
 	case d.tp < len(d.tail.v):
 		// There's room in the tail slice.
 		d.tail.v[d.tp] = v
 		d.tp++
-	case d.tp < maxFirstSliceSize:
+	case d.tp < d.config().maxFirstSliceSize:
 		// We're on the first slice and it hasn't grown large enough yet.
-		nv := make([]interface{}, len(d.tail.v)*sliceGrowthFactor)
+		nv := make([]interface{}, d.config().grow(len(d.tail.v)))
 		copy(nv, d.tail.v)
 		d.tail.v = nv
 		d.tail.v[d.tp] = v
 		d.tp++
-		d.hlp = len(nv) - 1
+		if d.tail == d.head {
+			d.hlp = len(nv) - 1
+		}
 	case d.tail.n != d.head:
 		// There's at least one spare link between head and tail nodes.
 		n := d.tail.n
 		d.tail = n
 		d.tail.v[0] = v
 		d.tp = 1
+		d.spare--
 	default:
 		// No available nodes, so make one.
-		n := &node{v: make([]interface{}, maxInternalSliceSize)}
+		n := d.newNode()
 		n.n = d.head
+		n.p = d.tail
 		d.tail.n = n
+		d.head.p = n
 		d.tail = n
 		d.tail.v[0] = v
 		d.tp = 1
@@ -148,11 +401,93 @@ func (d *Queue) Push(v interface{}) {
 	d.len++
 }
 
+// PushSlice adds all values in vs to the back of the queue, in order.
+// The values are copied into the internal slices in runs, so PushSlice is
+// considerably faster than calling Push for each value.
+// The complexity is O(len(vs)).
+func (d *Queue) PushSlice(vs []interface{}) {
+	if debug {
+		d.debugEnter("PushSlice")
+		defer d.debugExit()
+	}
+	for len(vs) > 0 {
+		if d.head == nil || d.tp == len(d.tail.v) {
+			// The tail slice is full, so let Push either grow it, move to a
+			// spare node or make a new one.
+			d.push(vs[0])
+			vs = vs[1:]
+			continue
+		}
+		n := copy(d.tail.v[d.tp:], vs)
+		d.tp += n
+		d.len += n
+		vs = vs[n:]
+	}
+}
+
+// Back returns the last element of queue d or nil if the queue is empty.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *Queue) Back() (interface{}, bool) {
+	if debug {
+		d.debugEnterRead("Back")
+		defer d.debugExitRead()
+	}
+	if d.len == 0 {
+		return nil, false
+	}
+	return d.tail.v[d.tp-1], true
+}
+
+// PushFront adds value v to the the front of the queue.
+// The complexity is O(1).
+func (d *Queue) PushFront(v interface{}) {
+	if debug {
+		d.debugEnter("PushFront")
+		defer d.debugExit()
+	}
+	switch {
+	case d.len == 0:
+		// An empty queue has its head and tail at the same position,
+		// so pushing to the front is the same as pushing to the back.
+		d.push(v)
+		return
+	case d.hp > 0:
+		// There's room before the first element in the head slice.
+		d.hp--
+		d.head.v[d.hp] = v
+	case d.head.p != d.tail:
+		// There's at least one spare link between tail and head nodes.
+		d.head = d.head.p
+		d.hlp = len(d.head.v) - 1
+		d.hp = d.hlp
+		d.head.v[d.hp] = v
+		d.spare--
+	default:
+		// No available nodes, so make one.
+		n := d.newNode()
+		n.n = d.head
+		n.p = d.tail
+		d.tail.n = n
+		d.head.p = n
+		d.head = n
+		d.hlp = len(n.v) - 1
+		d.hp = d.hlp
+		d.head.v[d.hp] = v
+	}
+	d.len++
+}
+
 // Pop retrieves and removes the current element from the front of the queue.
 // The second, bool result indicates whether a valid value was returned;
 // if the queue is empty, false will be returned.
 // The complexity is O(1).
 func (d *Queue) Pop() (interface{}, bool) {
+	if debug {
+		d.debugEnter("Pop")
+		defer d.debugExit()
+	}
 	if d.len == 0 {
 		return nil, false
 	}
@@ -175,6 +510,182 @@ func (d *Queue) Pop() (interface{}, bool) {
 		d.hp = 0
 		d.head = d.head.n
 		d.hlp = len(d.head.v) - 1
+		d.spare++
+		if d.trim != nil {
+			d.trimSpare()
+		}
 	}
 	return v, true
 }
+
+// PopBack retrieves and removes the current element from the back of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1).
+func (d *Queue) PopBack() (interface{}, bool) {
+	if debug {
+		d.debugEnter("PopBack")
+		defer d.debugExit()
+	}
+	if d.len == 0 {
+		return nil, false
+	}
+
+	d.tp--
+	vp := &d.tail.v[d.tp]
+	v := *vp
+	*vp = nil // Avoid memory leaks
+	d.len--
+	if d.tp == 0 && d.head != d.tail {
+		// The tail slice is now empty, so move back to the previous slice,
+		// which is always full up to its end.
+		d.tail = d.tail.p
+		d.tp = len(d.tail.v)
+		d.spare++
+		if d.trim != nil {
+			d.trimSpare()
+		}
+	}
+	return v, true
+}
+
+// slot returns a pointer to the position holding the element at index i.
+// The head slice holds the first elements starting at hp and, as it may be the
+// still growing first slice, its length can differ from all the other slices,
+// so every node is measured by its own slice length.
+func (d *Queue) slot(i int) *interface{} {
+	if i < 0 || i >= d.len {
+		panic("queue: index out of range")
+	}
+	n, p := d.head, d.hp+i
+	for p >= len(n.v) {
+		p -= len(n.v)
+		n = n.n
+	}
+	return &n.v[p]
+}
+
+// PopN retrieves and removes up to n elements from the front of the queue,
+// returning them in order in a newly allocated slice.
+// If the queue holds less than n elements, all of them are returned.
+// The complexity is O(n).
+func (d *Queue) PopN(n int) []interface{} {
+	if debug {
+		d.debugEnter("PopN")
+		defer d.debugExit()
+	}
+	if n > d.len {
+		n = d.len
+	}
+	if n <= 0 {
+		return nil
+	}
+	return d.appendTo(make([]interface{}, 0, n), n)
+}
+
+// AppendTo retrieves and removes up to n elements from the front of the queue,
+// appending them in order to dst and returning the extended slice.
+// If the queue holds less than n elements, all of them are appended.
+// The values are copied out of the internal slices in runs, so AppendTo is
+// considerably faster than calling Pop for each element.
+// The complexity is O(n).
+func (d *Queue) AppendTo(dst []interface{}, n int) []interface{} {
+	if debug {
+		d.debugEnter("AppendTo")
+		defer d.debugExit()
+	}
+	return d.appendTo(dst, n)
+}
+
+// appendTo removes up to n elements from the front of queue d, appending them
+// to dst. See AppendTo.
+func (d *Queue) appendTo(dst []interface{}, n int) []interface{} {
+	if n > d.len {
+		n = d.len
+	}
+	for n > 0 {
+		end := d.hlp + 1
+		if d.head == d.tail {
+			end = d.tp
+		}
+		if end-d.hp > n {
+			end = d.hp + n
+		}
+		run := d.head.v[d.hp:end]
+		dst = append(dst, run...)
+		clearValues(run) // Avoid memory leaks
+		n -= len(run)
+		d.len -= len(run)
+		d.advanceHead(end)
+	}
+	if d.trim != nil {
+		d.trimSpare()
+	}
+	return dst
+}
+
+// advanceHead moves the head of queue d to position hp of the head slice,
+// right after the last removed element, moving on to the next slice if the
+// head slice was fully consumed.
+func (d *Queue) advanceHead(hp int) {
+	d.hp = hp
+	switch {
+	case d.hp <= d.hlp:
+		// The head still has elements or, in an empty queue, room for new ones.
+	case d.head == d.tail:
+		// The single slice was fully consumed up to its end, so we can't
+		// move hp beyond it; change tp instead, just like Pop does.
+		d.hp = d.hlp
+		d.tp = d.hlp
+	default:
+		// Move to the next slice.
+		d.hp = 0
+		d.head = d.head.n
+		d.hlp = len(d.head.v) - 1
+		d.spare++
+	}
+}
+
+// clearValues sets all values in vs to nil.
+func clearValues(vs []interface{}) {
+	for i := range vs {
+		vs[i] = nil
+	}
+}
+
+// trimSpare releases the spare nodes above the limits of the trim policy.
+// Each node is released at most once after being created, so the amortized
+// complexity is O(1).
+func (d *Queue) trimSpare() {
+	limit := d.len * d.trim.MaxSparePercent / 100 / d.config().maxInternalSliceSize
+	if limit < d.trim.MaxSpareNodes {
+		limit = d.trim.MaxSpareNodes
+	}
+	for d.spare > limit {
+		// Release the spare node right after the tail.
+		n := d.tail.n
+		d.tail.n = n.n
+		n.n.p = d.tail
+		d.spare--
+		d.release(n)
+	}
+}
+
+// newNode returns a new, empty node to be linked in the ring of queue d,
+// taking it from the allocator if queue d uses one.
+func (d *Queue) newNode() *node {
+	c := d.config()
+	if c.alloc != nil {
+		return c.alloc.get()
+	}
+	return &node{v: make([]interface{}, c.maxInternalSliceSize)}
+}
+
+// release returns node n, already unlinked from the ring and holding no values,
+// to the allocator if queue d uses one. Nodes not created by the allocator,
+// such as the first slice, are left to the garbage collector.
+func (d *Queue) release(n *node) {
+	if d.cfg != nil && d.cfg.alloc != nil && len(n.v) == d.cfg.alloc.size {
+		d.cfg.alloc.put(n)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ShardedQueue implements a queue that is safe for concurrent use and spreads
+// its values over a number of shards, each one a Queue protected by its own
+// mutex, reducing the contention of a single mutex protected queue.
+//
+// Ordering is relaxed: values pushed to the same shard are always popped in
+// the order they were pushed (per-shard FIFO), but there's no ordering among
+// values in different shards. Values pushed with the same key always go to
+// the same shard, so PushKey keeps FIFO ordering for each key.
+//
+// ShardedQueue must be created with NewSharded.
+type ShardedQueue struct {
+	// shards holds the queue shards.
+	shards []shard
+
+	// push holds the round-robin counter used by Push to pick shards.
+	push atomic.Uint64
+
+	_ cacheLinePad
+
+	// pop holds the round-robin counter used by Pop to pick the first shard
+	// to pop from.
+	pop atomic.Uint64
+}
+
+// shard represents a ShardedQueue shard.
+type shard struct {
+	// mu protects q.
+	mu sync.Mutex
+
+	// q holds the shard values.
+	q Queue
+
+	_ cacheLinePad
+}
+
+// NewSharded returns an initialized sharded queue with n shards.
+// NewSharded panics if n is less than 1.
+func NewSharded(n int) *ShardedQueue {
+	if n < 1 {
+		panic("queue: number of shards must be greater than zero")
+	}
+	return &ShardedQueue{shards: make([]shard, n)}
+}
+
+// Shards returns the number of shards of queue d.
+func (d *ShardedQueue) Shards() int { return len(d.shards) }
+
+// Len returns the number of elements of queue d. As the shards are counted one
+// at a time, the result is only a snapshot when there are concurrent pushes
+// and pops.
+// The complexity is O(shards).
+func (d *ShardedQueue) Len() int {
+	l := 0
+	for i := range d.shards {
+		s := &d.shards[i]
+		s.mu.Lock()
+		l += s.q.Len()
+		s.mu.Unlock()
+	}
+	return l
+}
+
+// Push adds value v to the the back of the next shard, picking the shards in a
+// round-robin fashion.
+// The complexity is O(1).
+func (d *ShardedQueue) Push(v interface{}) {
+	d.pushShard(int((d.push.Add(1)-1)%uint64(len(d.shards))), v)
+}
+
+// PushKey adds value v to the back of the shard picked by key, so values pushed
+// with the same key are always popped in the order they were pushed.
+// Keys such as strings can be hashed with hash/maphash.
+// The complexity is O(1).
+func (d *ShardedQueue) PushKey(key uint64, v interface{}) {
+	d.pushShard(int(key%uint64(len(d.shards))), v)
+}
+
+// Pop retrieves and removes the current element from the front of the next
+// shard, picking the first shard in a round-robin fashion and stealing from
+// the other shards if it's empty.
+// The second, bool result indicates whether a valid value was returned;
+// if all shards are empty, false will be returned.
+// The complexity is O(1) if the first shard isn't empty, and O(shards) otherwise.
+func (d *ShardedQueue) Pop() (interface{}, bool) {
+	return d.PopFrom(int((d.pop.Add(1) - 1) % uint64(len(d.shards))))
+}
+
+// PopFrom retrieves and removes the current element from the front of the
+// given shard, stealing from the other shards if it's empty. Consumers that
+// always pop from their own shard avoid contending with each other while
+// there are values in their shards.
+// The second, bool result indicates whether a valid value was returned;
+// if all shards are empty, false will be returned.
+// PopFrom panics if shard is out of range.
+// The complexity is O(1) if the shard isn't empty, and O(shards) otherwise.
+func (d *ShardedQueue) PopFrom(shard int) (interface{}, bool) {
+	if shard < 0 || shard >= len(d.shards) {
+		panic("queue: shard out of range")
+	}
+	for i := 0; i < len(d.shards); i++ {
+		s := &d.shards[(shard+i)%len(d.shards)]
+		s.mu.Lock()
+		v, ok := s.q.Pop()
+		s.mu.Unlock()
+		if ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// pushShard adds value v to the back of shard i.
+func (d *ShardedQueue) pushShard(i int, v interface{}) {
+	s := &d.shards[i]
+	s.mu.Lock()
+	s.q.Push(v)
+	s.mu.Unlock()
+}
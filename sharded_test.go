@@ -0,0 +1,190 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestNewShardedWithInvalidShardsShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected: panic; Got: none")
+		}
+	}()
+	queue.NewSharded(0)
+}
+
+func TestPopFromWithOutOfRangeShardShouldPanic(t *testing.T) {
+	q := queue.NewSharded(2)
+	for _, shard := range []int{-1, 2} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("PopFrom(%d): Expected: panic; Got: none", shard)
+				}
+			}()
+			q.PopFrom(shard)
+		}()
+	}
+}
+
+func TestShardedQueueWhenEmptyShouldReturnAsEmpty(t *testing.T) {
+	q := queue.NewSharded(4)
+
+	if s := q.Shards(); s != 4 {
+		t.Errorf("Expected: 4; Got: %d", s)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if l := q.Len(); l != 0 {
+		t.Errorf("Expected: 0 as the queue is empty; Got: %d", l)
+	}
+}
+
+func TestShardedQueueWithSingleShardShouldRetrieveAllElementsInOrder(t *testing.T) {
+	q := queue.NewSharded(1)
+
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < pushCount; i++ {
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %d", i, v)
+		}
+	}
+}
+
+func TestPushKeyShouldKeepFIFOOrderPerKey(t *testing.T) {
+	const keys = 10
+	q := queue.NewSharded(4)
+
+	for i := 0; i < pushCount; i++ {
+		q.PushKey(uint64(i%keys), i)
+	}
+	if l := q.Len(); l != pushCount {
+		t.Errorf("Expected: %d; Got: %d", pushCount, l)
+	}
+	last := make([]int, keys)
+	for i := range last {
+		last[i] = -1
+	}
+	for i := 0; i < pushCount; i++ {
+		v, ok := q.Pop()
+		if !ok {
+			t.Fatalf("Expected: value; Got: empty queue")
+		}
+		k := v.(int) % keys
+		if v.(int) <= last[k] {
+			t.Errorf("Expected: value after %d for key %d; Got: %d", last[k], k, v)
+		}
+		last[k] = v.(int)
+	}
+}
+
+func TestPopFromShouldStealFromOtherShards(t *testing.T) {
+	q := queue.NewSharded(4)
+	q.PushKey(2, 1)
+
+	if v, ok := q.PopFrom(3); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	if _, ok := q.PopFrom(0); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+}
+
+func TestShardedQueueWithConcurrentProducersAndConsumersShouldDeliverAllValues(t *testing.T) {
+	const producers, consumers = 4, 4
+	q := queue.NewSharded(4)
+
+	var pwg, cwg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < pushCount; i++ {
+				q.PushKey(uint64(p), p*pushCount+i)
+			}
+		}(p)
+	}
+	pwg.Wait()
+
+	seen := make([][]int, consumers)
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, ok := q.PopFrom(c)
+				if !ok {
+					return
+				}
+				seen[c] = append(seen[c], v.(int))
+			}
+		}(c)
+	}
+	cwg.Wait()
+
+	received := make([]int, producers*pushCount)
+	for _, vs := range seen {
+		for _, v := range vs {
+			received[v]++
+		}
+	}
+	for v, c := range received {
+		if c != 1 {
+			t.Errorf("Expected: value %d received once; Got: %d times", v, c)
+		}
+	}
+}
+
+func BenchmarkShardedQueue(b *testing.B) {
+	for _, procs := range []int{1, 2, 4, 8} {
+		b.Run("procs-"+strconv.Itoa(procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			b.Run("sharded", func(b *testing.B) {
+				q := queue.NewSharded(procs)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						q.Push(nil)
+						q.Pop()
+					}
+				})
+			})
+			b.Run("mutex", func(b *testing.B) {
+				q := queue.NewBlocking()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						q.Push(nil)
+						q.TryPop()
+					}
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "sync/atomic"
+
+// SPSCQueue implements a wait-free, unbounded First-In-First-Out (FIFO) queue
+// that is safe for concurrent use by exactly one producer goroutine, calling
+// Push, and one consumer goroutine, calling Pop.
+//
+// SPSCQueue uses the same dynamic growing circular linked list of arrays as
+// Queue: the producer owns the tail node and the tp index, while the consumer
+// owns the head node and the hp index, so neither one ever waits for the other.
+// Nodes emptied by the consumer are reused by the producer as spare nodes,
+// just like Queue does. Unlike Queue, all nodes have the fixed size of
+// maxInternalSliceSize, as growing the first slice would require copying
+// values the consumer may be reading.
+//
+// Memory ordering: the producer writes each value into its slot before
+// atomically publishing the number of written slots of the node, and links
+// and initializes a node before atomically publishing it as the tail. The
+// consumer only reads a slot after observing its publication. So everything
+// the producer did before pushing a value happens before the consumer's Pop
+// returns that value, as defined by the Go memory model. Likewise, the consumer
+// clears every slot of a node and resets its published count before atomically
+// publishing the next node as the head, which is what the producer checks
+// before reusing the node.
+//
+// SPSCQueue must be created with NewSPSC.
+type SPSCQueue struct {
+	_ cacheLinePad
+
+	// head points to the node values are currently popped from.
+	// Written only by the consumer.
+	head atomic.Pointer[spscNode]
+
+	// hp is the index of the next value to pop in the head node.
+	// Used only by the consumer.
+	hp int
+
+	_ cacheLinePad
+
+	// tail points to the node values are currently pushed to.
+	// Written only by the producer.
+	tail atomic.Pointer[spscNode]
+
+	// tp is the index one beyond the last value pushed in the tail node.
+	// Used only by the producer.
+	tp int
+
+	_ cacheLinePad
+}
+
+// spscNode represents a SPSCQueue node.
+type spscNode struct {
+	// v holds the node values.
+	v []interface{}
+
+	// w holds the number of values written and published in the node.
+	w atomic.Int64
+
+	// n points to the next node in the linked list.
+	n atomic.Pointer[spscNode]
+}
+
+// NewSPSC returns an initialized wait-free single-producer single-consumer queue.
+func NewSPSC() *SPSCQueue {
+	d := new(SPSCQueue)
+	n := &spscNode{v: make([]interface{}, maxInternalSliceSize)}
+	n.n.Store(n)
+	d.head.Store(n)
+	d.tail.Store(n)
+	return d
+}
+
+// Push adds value v to the the back of the queue.
+// Push must only be called by the producer goroutine.
+// The complexity is O(1).
+func (d *SPSCQueue) Push(v interface{}) {
+	t := d.tail.Load()
+	if d.tp < len(t.v) {
+		// There's room in the tail slice.
+		t.v[d.tp] = v
+		d.tp++
+		t.w.Store(int64(d.tp))
+		return
+	}
+
+	n := t.n.Load()
+	if n == d.head.Load() {
+		// No spare nodes between tail and head, so make one.
+		nn := &spscNode{v: make([]interface{}, maxInternalSliceSize)}
+		nn.n.Store(n)
+		t.n.Store(nn)
+		n = nn
+	}
+	n.v[0] = v
+	n.w.Store(1)
+	d.tp = 1
+	d.tail.Store(n)
+}
+
+// Pop retrieves and removes the current element from the front of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// Pop must only be called by the consumer goroutine.
+// The complexity is O(1).
+func (d *SPSCQueue) Pop() (interface{}, bool) {
+	h := d.head.Load()
+	if d.hp == len(h.v) {
+		// The head node is fully consumed. Move on to the next node only
+		// once the producer did, as only then the next node is known.
+		if d.tail.Load() == h {
+			return nil, false
+		}
+		n := h.n.Load()
+		h.w.Store(0)
+		d.head.Store(n)
+		d.hp = 0
+		h = n
+	}
+	if int64(d.hp) >= h.w.Load() {
+		return nil, false
+	}
+	v := h.v[d.hp]
+	h.v[d.hp] = nil // Avoid memory leaks
+	d.hp++
+	return v, true
+}
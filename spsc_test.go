@@ -0,0 +1,152 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestSPSCWhenEmptyShouldReturnAsEmpty(t *testing.T) {
+	q := queue.NewSPSC()
+
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	q.Push(nil)
+	if v, ok := q.Pop(); !ok || v != nil {
+		t.Errorf("Expected: nil; Got: %v", v)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+}
+
+func TestSPSCRefillShouldRetrieveAllElementsInOrder(t *testing.T) {
+	q := queue.NewSPSC()
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < pushCount; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Errorf("Expected: %d; Got: %d", j, v)
+			}
+		}
+		if _, ok := q.Pop(); ok {
+			t.Error("Expected: false as the queue is empty; Got: true")
+		}
+	}
+}
+
+func TestSPSCWithConcurrentProducerAndConsumerShouldDeliverAllValuesInOrder(t *testing.T) {
+	q := queue.NewSPSC()
+	count := pushCount * refillCount * 10
+
+	go func() {
+		for i := 0; i < count; i++ {
+			q.Push(i)
+		}
+	}()
+	for i := 0; i < count; {
+		v, ok := q.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		if v.(int) != i {
+			t.Fatalf("Expected: %d; Got: %d", i, v)
+		}
+		i++
+	}
+}
+
+func TestSPSCShouldPublishWritesDoneBeforePush(t *testing.T) {
+	// Run with the race detector: the consumer reading the fields written by
+	// the producer before Push must not be reported as a data race.
+	type message struct {
+		id   int
+		body []int
+	}
+	q := queue.NewSPSC()
+	count := pushCount * refillCount
+
+	go func() {
+		for i := 0; i < count; i++ {
+			m := &message{id: i}
+			m.body = append(m.body, i, i+1)
+			q.Push(m)
+		}
+	}()
+	for i := 0; i < count; {
+		v, ok := q.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		m := v.(*message)
+		if m.id != i || len(m.body) != 2 || m.body[1] != i+1 {
+			t.Fatalf("Expected: message %d; Got: %+v", i, m)
+		}
+		i++
+	}
+}
+
+func BenchmarkSPSC(b *testing.B) {
+	q := queue.NewSPSC()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < b.N; {
+			if _, ok := q.Pop(); ok {
+				i++
+				continue
+			}
+			runtime.Gosched()
+		}
+		close(done)
+	}()
+	for i := 0; i < b.N; i++ {
+		q.Push(nil)
+	}
+	<-done
+}
+
+func BenchmarkSPSCMutexQueue(b *testing.B) {
+	q := queue.NewBlocking()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < b.N; {
+			if _, ok := q.TryPop(); ok {
+				i++
+				continue
+			}
+			runtime.Gosched()
+		}
+		close(done)
+	}()
+	for i := 0; i < b.N; i++ {
+		q.Push(nil)
+	}
+	<-done
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "testing"
+
+func TestSPSCShouldReuseSpareNodes(t *testing.T) {
+	q := NewSPSC()
+	size := func() int {
+		size := 1
+		for n := q.head.Load().n.Load(); n != q.head.Load(); n = n.n.Load() {
+			size++
+		}
+		return size
+	}
+
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < pushCount; i++ {
+		q.Pop()
+	}
+	nodes := size()
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < pushCount; j++ {
+			if v, ok := q.Pop(); !ok || v.(int) != j {
+				t.Fatalf("Expected: %d; Got: %d", j, v)
+			}
+		}
+	}
+	// Refilling needs at most one extra node, as the head may not be at the
+	// start of its slice.
+	if n := size(); n > nodes+1 {
+		t.Errorf("Expected: at most %d nodes; Got: %d", nodes+1, n)
+	}
+}
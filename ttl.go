@@ -0,0 +1,148 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"time"
+
+	"github.com/ef-ds/queue/generic"
+)
+
+// TTLQueue implements a First-In-First-Out (FIFO) queue where each value
+// expires once its time to live (TTL) elapses. Pop and Front transparently
+// discard the expired values at the front of the queue, reporting each one to
+// the expire callback.
+//
+// Len includes the expired values not discarded yet. As values are only
+// discarded once they reach the front of the queue, an expired value stays in
+// the queue, and is counted by Len, while a value pushed before it hasn't
+// expired nor been popped.
+//
+// TTLQueue is not safe for concurrent use.
+// TTLQueue must be created with NewTTL or NewTTLWithClock.
+type TTLQueue struct {
+	// q holds the queue values and their expiry times.
+	q generic.Queue[ttlEntry]
+
+	// ttl holds the time to live of the values pushed with Push.
+	ttl time.Duration
+
+	// onExpire is called with every expired value discarded.
+	// A nil onExpire means expired values are discarded silently.
+	onExpire func(v interface{})
+
+	// expired holds the number of expired values discarded.
+	expired int
+
+	// clock holds the clock used to tell whether values expired.
+	clock Clock
+}
+
+// ttlEntry represents a TTLQueue value.
+type ttlEntry struct {
+	// v holds the user added value.
+	v interface{}
+
+	// expiry holds the time v expires at.
+	expiry time.Time
+}
+
+// NewTTL returns an initialized TTL queue where the values pushed with Push
+// expire once ttl elapses.
+// onExpire, if not nil, is called with every expired value discarded.
+// NewTTL panics if ttl is not positive.
+func NewTTL(ttl time.Duration, onExpire func(v interface{})) *TTLQueue {
+	return NewTTLWithClock(ttl, onExpire, systemClock{})
+}
+
+// NewTTLWithClock returns an initialized TTL queue, just like NewTTL, that
+// uses clock c to tell whether values expired.
+// NewTTLWithClock panics if ttl is not positive or c is nil.
+func NewTTLWithClock(ttl time.Duration, onExpire func(v interface{}), c Clock) *TTLQueue {
+	if ttl <= 0 {
+		panic("queue: TTL must be greater than zero")
+	}
+	if c == nil {
+		panic("queue: nil clock")
+	}
+	return &TTLQueue{ttl: ttl, onExpire: onExpire, clock: c}
+}
+
+// Len returns the number of elements of queue d, including the expired
+// elements not discarded yet.
+// The complexity is O(1).
+func (d *TTLQueue) Len() int { return d.q.Len() }
+
+// Expired returns the number of expired values discarded so far.
+// The complexity is O(1).
+func (d *TTLQueue) Expired() int { return d.expired }
+
+// Front returns the first not expired element of queue d or nil if there's
+// none, discarding the expired elements before it.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty or all its elements expired, false will be returned.
+// The complexity is O(1), plus O(1) per discarded element.
+func (d *TTLQueue) Front() (interface{}, bool) {
+	d.discardExpired()
+	e, ok := d.q.Front()
+	return e.v, ok
+}
+
+// Push adds value v to the the back of the queue, to expire once the queue
+// TTL elapses.
+// The complexity is O(1).
+func (d *TTLQueue) Push(v interface{}) {
+	d.PushTTL(v, d.ttl)
+}
+
+// PushTTL adds value v to the the back of the queue, to expire once ttl
+// elapses. Values with a non positive ttl are already expired.
+// The complexity is O(1).
+func (d *TTLQueue) PushTTL(v interface{}, ttl time.Duration) {
+	d.q.Push(ttlEntry{v: v, expiry: d.clock.Now().Add(ttl)})
+}
+
+// Pop retrieves and removes the first not expired element from the front of
+// the queue, discarding the expired elements before it.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty or all its elements expired, false will be returned.
+// The complexity is O(1), plus O(1) per discarded element.
+func (d *TTLQueue) Pop() (interface{}, bool) {
+	d.discardExpired()
+	e, ok := d.q.Pop()
+	return e.v, ok
+}
+
+// discardExpired discards the expired elements at the front of queue d.
+func (d *TTLQueue) discardExpired() {
+	now := d.clock.Now()
+	for {
+		e, ok := d.q.Front()
+		if !ok || now.Before(e.expiry) {
+			return
+		}
+		d.q.Pop()
+		d.expired++
+		if d.onExpire != nil {
+			d.onExpire(e.v)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestTTLQueueShouldReturnValuesNotExpired(t *testing.T) {
+	q := queue.NewTTL(time.Hour, nil)
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+
+	for i := 0; i < pushCount; i++ {
+		if v, ok := q.Front(); !ok || v.(int) != i {
+			t.Fatalf("Expected: %d; Got: %v", i, v)
+		}
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Fatalf("Expected: %d; Got: %v", i, v)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if e := q.Expired(); e != 0 {
+		t.Errorf("Expected: 0; Got: %d", e)
+	}
+}
+
+func TestTTLQueueWithMaxTTLShouldNeverExpireValues(t *testing.T) {
+	q := queue.NewTTL(math.MaxInt64, nil)
+	q.Push(1)
+	q.PushTTL(2, math.MaxInt64)
+
+	for i := 1; i <= 2; i++ {
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %v", i, v)
+		}
+	}
+	if e := q.Expired(); e != 0 {
+		t.Errorf("Expected: 0; Got: %d", e)
+	}
+}
+
+func TestTTLQueueShouldDiscardAndReportExpiredValues(t *testing.T) {
+	c := newFakeClock()
+	var expired []interface{}
+	q := queue.NewTTLWithClock(10*time.Second, func(v interface{}) { expired = append(expired, v) }, c)
+	q.Push(1)
+	q.Push(2)
+	c.Advance(5 * time.Second)
+	q.Push(3)
+
+	c.Advance(5 * time.Second)
+	// Expired values are counted until discarded.
+	if l := q.Len(); l != 3 {
+		t.Errorf("Expected: 3; Got: %d", l)
+	}
+	if v, ok := q.Front(); !ok || v.(int) != 3 {
+		t.Errorf("Expected: 3; Got: %v", v)
+	}
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+	if e := q.Expired(); e != 2 || len(expired) != 2 || expired[0].(int) != 1 || expired[1].(int) != 2 {
+		t.Errorf("Expected: 2 expired values [1 2]; Got: %d %v", e, expired)
+	}
+
+	c.Advance(5 * time.Second)
+	if v, ok := q.Pop(); ok {
+		t.Errorf("Expected: false as all values expired; Got: %v", v)
+	}
+	if e := q.Expired(); e != 3 || len(expired) != 3 {
+		t.Errorf("Expected: 3 expired values; Got: %d %v", e, expired)
+	}
+}
+
+func TestTTLQueueShouldOnlyDiscardExpiredValuesAtTheFront(t *testing.T) {
+	c := newFakeClock()
+	q := queue.NewTTLWithClock(time.Minute, nil, c)
+	q.Push(1)
+	q.PushTTL(2, time.Second)
+	q.PushTTL(3, 0) // Already expired.
+	q.Push(4)
+
+	c.Advance(time.Second)
+	if v, ok := q.Pop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	if v, ok := q.Pop(); !ok || v.(int) != 4 {
+		t.Errorf("Expected: 4; Got: %v", v)
+	}
+	if e := q.Expired(); e != 2 {
+		t.Errorf("Expected: 2; Got: %d", e)
+	}
+}
+
+func TestNewTTLWithInvalidArgumentsShouldPanic(t *testing.T) {
+	tests := map[string]func(){
+		"zero ttl":  func() { queue.NewTTL(0, nil) },
+		"nil clock": func() { queue.NewTTLWithClock(time.Second, nil, nil) },
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("Expected: panic; Got: none")
+				}
+			}()
+			test()
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+// UniqueQueue implements a First-In-First-Out (FIFO) queue that holds at most
+// one pending value per key, as returned by a user supplied key function.
+// Pushing a value whose key is already pending either leaves the queue
+// unchanged (Push) or replaces the pending value in place, keeping its position
+// in the queue (PushOrReplace).
+//
+// Removed values are discarded once they reach the front of the queue, so until
+// then, they still hold a position in the queue, but are not counted by Len.
+//
+// UniqueQueue is not safe for concurrent use.
+// UniqueQueue must be created with NewUnique.
+type UniqueQueue struct {
+	// q holds the pending values.
+	q keyedQueue
+}
+
+// NewUnique returns an initialized unique queue that uses key to get the key
+// of its values. Keys must be comparable.
+// NewUnique panics if key is nil.
+func NewUnique(key func(v interface{}) interface{}) *UniqueQueue {
+	return &UniqueQueue{q: newKeyedQueue(key)}
+}
+
+// Len returns the number of pending elements of queue d.
+// The complexity is O(1).
+func (d *UniqueQueue) Len() int { return d.q.len() }
+
+// Contains returns whether there's a pending element with key in queue d.
+// The complexity is O(1).
+func (d *UniqueQueue) Contains(key interface{}) bool { return d.q.contains(key) }
+
+// Front returns the first element of queue d or nil if the queue is empty.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1), plus O(1) per discarded removed element.
+func (d *UniqueQueue) Front() (interface{}, bool) { return d.q.front() }
+
+// Push adds value v to the the back of the queue, unless an element with the
+// same key is pending, in which case the queue is left unchanged.
+// The result indicates whether v was added.
+// The complexity is O(1).
+func (d *UniqueQueue) Push(v interface{}) bool {
+	_, added := d.q.push(v)
+	return added
+}
+
+// PushOrReplace adds value v to the the back of the queue, unless an element
+// with the same key is pending, in which case the pending element is replaced
+// with v, keeping its position in the queue.
+// The result indicates whether v was added to the back of the queue.
+// The complexity is O(1).
+func (d *UniqueQueue) PushOrReplace(v interface{}) bool {
+	e, added := d.q.push(v)
+	if !added {
+		e.v = v
+	}
+	return added
+}
+
+// Pop retrieves and removes the current element from the front of the queue.
+// The second, bool result indicates whether a valid value was returned;
+// if the queue is empty, false will be returned.
+// The complexity is O(1), plus O(1) per discarded removed element.
+func (d *UniqueQueue) Pop() (interface{}, bool) { return d.q.pop() }
+
+// Remove removes the pending element with key from queue d.
+// The result indicates whether an element was removed.
+// The complexity is amortized O(1).
+func (d *UniqueQueue) Remove(key interface{}) bool { return d.q.remove(key) }
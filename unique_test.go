@@ -0,0 +1,131 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"testing"
+
+	"github.com/ef-ds/queue"
+)
+
+// change represents a change notification, identified by the changed entity ID.
+type change struct {
+	id      int
+	version int
+}
+
+func changeID(v interface{}) interface{} { return v.(change).id }
+
+func TestUniqueQueueShouldIgnoreValuesWithPendingKey(t *testing.T) {
+	q := queue.NewUnique(changeID)
+	for i := 0; i < pushCount; i++ {
+		if added := q.Push(change{id: i % 10, version: i}); added != (i < 10) {
+			t.Fatalf("Expected: %t; Got: %t", i < 10, added)
+		}
+	}
+	if l := q.Len(); l != 10 {
+		t.Errorf("Expected: 10; Got: %d", l)
+	}
+
+	for i := 0; i < 10; i++ {
+		if v, ok := q.Front(); !ok || v.(change) != (change{id: i, version: i}) {
+			t.Fatalf("Expected: %v; Got: %v", change{id: i, version: i}, v)
+		}
+		if v, ok := q.Pop(); !ok || v.(change) != (change{id: i, version: i}) {
+			t.Fatalf("Expected: %v; Got: %v", change{id: i, version: i}, v)
+		}
+		if q.Contains(i) {
+			t.Errorf("Expected: %d not pending once popped; Got: pending", i)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+
+	// Keys are pending again once popped.
+	if !q.Push(change{id: 0}) {
+		t.Error("Expected: true; Got: false")
+	}
+}
+
+func TestUniqueQueuePushOrReplaceShouldReplacePendingValueInPlace(t *testing.T) {
+	q := queue.NewUnique(changeID)
+	q.Push(change{id: 1, version: 1})
+	q.Push(change{id: 2, version: 1})
+	if q.PushOrReplace(change{id: 1, version: 2}) {
+		t.Error("Expected: false as 1 is pending; Got: true")
+	}
+	if !q.PushOrReplace(change{id: 3, version: 1}) {
+		t.Error("Expected: true; Got: false")
+	}
+
+	for _, want := range []change{{1, 2}, {2, 1}, {3, 1}} {
+		if v, ok := q.Pop(); !ok || v.(change) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+	}
+}
+
+func TestUniqueQueueRemoveShouldRemovePendingValue(t *testing.T) {
+	q := queue.NewUnique(changeID)
+	for i := 0; i < 4; i++ {
+		q.Push(change{id: i})
+	}
+
+	if !q.Remove(0) || !q.Remove(2) {
+		t.Error("Expected: true; Got: false")
+	}
+	if q.Remove(2) || q.Remove(5) {
+		t.Error("Expected: false as the keys aren't pending; Got: true")
+	}
+	if q.Contains(0) || !q.Contains(1) {
+		t.Error("Expected: 0 removed and 1 pending")
+	}
+	if l := q.Len(); l != 2 {
+		t.Errorf("Expected: 2; Got: %d", l)
+	}
+
+	// Removed keys can be pushed again, to the back of the queue.
+	q.Push(change{id: 0, version: 1})
+	for _, want := range []change{{1, 0}, {3, 0}, {0, 1}} {
+		if v, ok := q.Front(); !ok || v.(change) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+		if v, ok := q.Pop(); !ok || v.(change) != want {
+			t.Errorf("Expected: %v; Got: %v", want, v)
+		}
+	}
+	if _, ok := q.Front(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected: false as the queue is empty; Got: true")
+	}
+}
+
+func TestNewUniqueWithNilKeyShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected: panic; Got: none")
+		}
+	}()
+	queue.NewUnique(nil)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import "testing"
+
+func TestUniqueQueueShouldNotAccumulateRemovedElements(t *testing.T) {
+	q := NewUnique(func(v interface{}) interface{} { return v })
+	q.Push(-1)
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+		q.Remove(i)
+	}
+	if l := q.Len(); l != 1 {
+		t.Errorf("Expected: 1; Got: %d", l)
+	}
+	if l := q.q.q.Len(); l > 2*q.Len()+1 {
+		t.Errorf("Expected: at most %d entries; Got: %d", 2*q.Len()+1, l)
+	}
+	if v, ok := q.Pop(); !ok || v.(int) != -1 {
+		t.Errorf("Expected: -1; Got: %v", v)
+	}
+}
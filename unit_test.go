@@ -20,9 +20,7 @@
 
 package queue
 
-import (
-	"testing"
-)
+import "testing"
 
 const (
 	refillCount = 3
@@ -132,8 +130,404 @@ func TestPushPopShouldHaveAllInternalLinksInARing(t *testing.T) {
 	}
 }
 
+func TestPushFrontPopBackShouldHaveAllInternalLinksInARing(t *testing.T) {
+	q := New()
+
+	// PushFront enough items to force the creation of new slices before the first one.
+	for i := 0; i < pushCount; i++ {
+		q.PushFront(i)
+		assertInvariants(t, q, func(j int) interface{} { return i - j })
+	}
+
+	// PopBack all items, moving the tail back over all the slices.
+	for i := 0; i < pushCount; i++ {
+		if v, ok := q.PopBack(); !ok || v.(int) != i {
+			t.Errorf("Expected: %d; Got: %d", i, v)
+		}
+		assertInvariants(t, q, func(j int) interface{} { return pushCount - 1 - j })
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected: %d; Got: %d", 0, q.Len())
+	}
+
+	// Push items again, reusing the spare slices in the ring.
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+		assertInvariants(t, q, func(j int) interface{} { return j })
+	}
+}
+
+func TestPushFrontShouldReuseSpareNodes(t *testing.T) {
+	q := New()
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < pushCount; i++ {
+		q.Pop()
+	}
+	q.Push(0)
+	nodes := ringSize(q)
+	for i := 1; i < pushCount; i++ {
+		q.PushFront(i)
+		assertInvariants(t, q, func(j int) interface{} { return i - j })
+	}
+	if n := ringSize(q); n != nodes {
+		t.Errorf("Expected: %d nodes in the ring; Got: %d", nodes, n)
+	}
+}
+
+func TestPushFrontOnSmallFirstSliceShouldKeepHeadSliceIndexes(t *testing.T) {
+	q := New()
+	q.Push(1)
+	q.PushFront(0)
+	assertInvariants(t, q, func(i int) interface{} { return i })
+
+	// Fill the small first slice, which is now the tail, forcing it to grow.
+	for i := 2; i < maxFirstSliceSize*2; i++ {
+		q.Push(i)
+		assertInvariants(t, q, func(j int) interface{} { return j })
+	}
+}
+
+func TestAtShouldAccountForVariableSizeHeadSlice(t *testing.T) {
+	q := New()
+
+	// Keep the head on the first slice while it grows, moving hp away from 0.
+	for i := 0; i < maxFirstSliceSize/2; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < maxFirstSliceSize/4; i++ {
+		q.Pop()
+	}
+	for i := maxFirstSliceSize / 2; i < pushCount; i++ {
+		q.Push(i)
+	}
+	if len(q.head.v) != maxFirstSliceSize || q.hp == 0 {
+		t.Fatalf("Expected: head on the grown first slice; Got: len %d, hp %d", len(q.head.v), q.hp)
+	}
+
+	first := maxFirstSliceSize / 4
+	for i := 0; i < q.Len(); i++ {
+		if v := q.At(i); v.(int) != first+i {
+			t.Errorf("Expected: %d; Got: %d", first+i, v)
+		}
+	}
+
+	// PushFront places a full size slice before the smaller first slice.
+	q.PushFront(-1)
+	q.PushFront(-2)
+	for i := 0; i < q.Len(); i++ {
+		q.Set(i, i)
+	}
+	assertInvariants(t, q, func(i int) interface{} { return i })
+}
+
+func TestPushSliceAppendToShouldKeepInvariants(t *testing.T) {
+	q := New()
+	vs := make([]interface{}, pushCount)
+	for i := range vs {
+		vs[i] = i
+	}
+
+	for i := 0; i < refillCount; i++ {
+		// Push in uneven runs to cross the first slice growth and node boundaries
+		// at different offsets.
+		for j, k := 0, 1; j < len(vs); j, k = j+k, k+7 {
+			end := j + k
+			if end > len(vs) {
+				end = len(vs)
+			}
+			q.PushSlice(vs[j:end])
+			assertInvariants(t, q, func(i int) interface{} { return i })
+		}
+		next := 0
+		for k := 1; q.Len() > 0; k += 5 {
+			for _, v := range q.AppendTo(nil, k) {
+				if v.(int) != next {
+					t.Fatalf("Expected: %d; Got: %d", next, v)
+				}
+				next++
+			}
+			assertInvariants(t, q, func(i int) interface{} { return next + i })
+		}
+		if next != pushCount {
+			t.Errorf("Expected: %d; Got: %d", pushCount, next)
+		}
+	}
+}
+
+func TestPushSliceShouldReuseSpareNodes(t *testing.T) {
+	q := New()
+	vs := make([]interface{}, pushCount)
+	q.PushSlice(vs)
+	q.PopN(pushCount)
+	q.PushSlice(vs)
+	nodes := ringSize(q)
+	for i := 0; i < refillCount; i++ {
+		q.PopN(pushCount)
+		q.PushSlice(vs)
+		assertInvariants(t, q, func(i int) interface{} { return nil })
+	}
+	if n := ringSize(q); n != nodes {
+		t.Errorf("Expected: %d nodes in the ring; Got: %d", nodes, n)
+	}
+}
+
+func TestShrinkShouldReleaseAllSpareNodes(t *testing.T) {
+	q := New()
+	q.Shrink()
+	assertInvariants(t, q, nil)
+
+	for i := 0; i < pushCount*refillCount; i++ {
+		q.Push(i)
+	}
+	for i := 0; i < pushCount*refillCount-1; i++ {
+		q.Pop()
+	}
+	if q.spare == 0 {
+		t.Fatal("Expected: spare nodes after popping; Got: none")
+	}
+
+	q.Shrink()
+	assertInvariants(t, q, func(int) interface{} { return pushCount*refillCount - 1 })
+	if size := ringSize(q); size != 1 {
+		t.Errorf("Expected: 1 node in the ring; Got: %d", size)
+	}
+
+	// The queue should still work, growing the ring again.
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+		q.PushFront(i)
+	}
+	assertInvariants(t, q, nil)
+}
+
+func TestTrimPolicyShouldKeepAtMostMaxSpareNodes(t *testing.T) {
+	q := New()
+	q.SetTrimPolicy(&TrimPolicy{MaxSpareNodes: 2})
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount*refillCount; j++ {
+			q.Push(j)
+		}
+		for q.Len() > 0 {
+			q.Pop()
+			assertInvariants(t, q, nil)
+			if q.spare > 2 {
+				t.Fatalf("Expected: at most 2 spare nodes; Got: %d", q.spare)
+			}
+		}
+		for j := 0; j < pushCount*refillCount; j++ {
+			q.PushFront(j)
+		}
+		for q.Len() > 0 {
+			q.PopBack()
+			assertInvariants(t, q, nil)
+			if q.spare > 2 {
+				t.Fatalf("Expected: at most 2 spare nodes; Got: %d", q.spare)
+			}
+		}
+	}
+}
+
+func TestTrimPolicyShouldKeepSpareCapacityProportionalToLen(t *testing.T) {
+	q := New()
+	q.SetTrimPolicy(&TrimPolicy{MaxSparePercent: 50})
+	for i := 0; i < pushCount*refillCount; i++ {
+		q.Push(i)
+	}
+	for q.Len() > 0 {
+		q.AppendTo(nil, maxInternalSliceSize/2)
+		assertInvariants(t, q, nil)
+		if limit := q.Len() / 2 / maxInternalSliceSize; q.spare > limit {
+			t.Fatalf("Expected: at most %d spare nodes; Got: %d", limit, q.spare)
+		}
+	}
+}
+
+func TestSetTrimPolicyShouldReleaseSpareNodesAboveTheLimits(t *testing.T) {
+	q := New()
+	for i := 0; i < pushCount*refillCount; i++ {
+		q.Push(i)
+	}
+	for q.Len() > 0 {
+		q.Pop()
+	}
+
+	q.SetTrimPolicy(&TrimPolicy{MaxSpareNodes: 1})
+	assertInvariants(t, q, nil)
+	if q.spare != 1 {
+		t.Errorf("Expected: 1 spare node; Got: %d", q.spare)
+	}
+
+	// Init should keep the policy, while nil should disable it.
+	q.Init()
+	if q.trim == nil {
+		t.Error("Expected: Init to keep the trim policy; Got: nil")
+	}
+	q.SetTrimPolicy(nil)
+	for i := 0; i < pushCount*refillCount; i++ {
+		q.Push(i)
+	}
+	for q.Len() > 0 {
+		q.Pop()
+	}
+	if q.spare <= 1 {
+		t.Errorf("Expected: spare nodes to be kept; Got: %d", q.spare)
+	}
+}
+
+func TestResetShouldKeepAllNodesAndClearAllValues(t *testing.T) {
+	q := New()
+	q.Reset()
+	assertInvariants(t, q, nil)
+
+	for i := 0; i < refillCount; i++ {
+		for j := 0; j < pushCount; j++ {
+			q.Push(j)
+			q.PushFront(j)
+		}
+		q.Pop()
+		q.PopBack()
+		nodes := ringSize(q)
+		q.Reset()
+		assertInvariants(t, q, nil)
+		if q.Len() != 0 {
+			t.Errorf("Expected: 0; Got: %d", q.Len())
+		}
+		if size := ringSize(q); size != nodes {
+			t.Errorf("Expected: %d nodes in the ring; Got: %d", nodes, size)
+		}
+		for n := q.head; ; n = n.n {
+			for _, v := range n.v {
+				if v != nil {
+					t.Fatalf("Expected: all values to be nil; Got: %v", v)
+				}
+			}
+			if n.n == q.head {
+				break
+			}
+		}
+	}
+}
+
+func TestResetShouldApplyTheTrimPolicy(t *testing.T) {
+	q := New()
+	q.SetTrimPolicy(&TrimPolicy{MaxSpareNodes: 1})
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+	}
+	q.Reset()
+	assertInvariants(t, q, nil)
+	if q.spare != 1 {
+		t.Errorf("Expected: 1 spare node; Got: %d", q.spare)
+	}
+}
+
+func TestReserveShouldAvoidAllocationsWhenPushing(t *testing.T) {
+	if debug {
+		t.Skip("the queuedebug checks allocate")
+	}
+	tests := map[string]func(q *Queue){
+		"zero value": func(q *Queue) {},
+		"small first slice": func(q *Queue) {
+			q.Push(nil)
+		},
+		"spare nodes": func(q *Queue) {
+			for i := 0; i < pushCount; i++ {
+				q.Push(nil)
+			}
+			for i := 0; i < pushCount-1; i++ {
+				q.Pop()
+			}
+		},
+		"small tail and spare nodes": func(q *Queue) {
+			// PushFront places full size nodes before the small first slice,
+			// which then becomes the tail, followed by the spare nodes.
+			q.Push(nil)
+			for i := 0; i < maxInternalSliceSize*2; i++ {
+				q.PushFront(nil)
+			}
+			for i := 0; i < maxInternalSliceSize*2; i++ {
+				q.Pop()
+			}
+		},
+		"small spare node": func(q *Queue) {
+			// PopBack moves the tail away from the small first slice,
+			// leaving it as a spare node.
+			q.Push(nil)
+			q.PushFront(nil)
+			q.PushFront(nil)
+			q.PopBack()
+		},
+	}
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, count := range []int{1, firstSliceSize + 1, maxFirstSliceSize, pushCount} {
+				const runs = 10
+				qs := make([]*Queue, runs+1)
+				for i := range qs {
+					qs[i] = New()
+					setup(qs[i])
+					qs[i].Reserve(count)
+				}
+				i := 0
+				allocs := testing.AllocsPerRun(runs, func() {
+					q := qs[i]
+					i++
+					for j := 0; j < count; j++ {
+						q.Push(nil)
+					}
+				})
+				if allocs != 0 {
+					t.Errorf("Reserve(%d): Expected: 0 allocations; Got: %v", count, allocs)
+				}
+				assertInvariants(t, qs[0], nil)
+			}
+		})
+	}
+}
+
+func TestInitWithAllocatorShouldReturnClearedNodes(t *testing.T) {
+	a := NewAllocator(maxInternalSliceSize)
+	q, _ := NewWithOptions(WithAllocator(a))
+	for i := 0; i < pushCount; i++ {
+		q.Push(i)
+		q.PushFront(i)
+	}
+	q.Init()
+	assertInvariants(t, q, nil)
+	if q.cfg.alloc != a {
+		t.Fatal("Expected: Init to keep the allocator; Got: nil")
+	}
+
+	for i := 0; i < 6; i++ {
+		n := a.get()
+		if n.n != nil || n.p != nil {
+			t.Fatal("Expected: unlinked node; Got: linked node")
+		}
+		for _, v := range n.v {
+			if v != nil {
+				t.Fatalf("Expected: all values to be nil; Got: %v", v)
+			}
+		}
+	}
+}
+
 // Helper methods-----------------------------------------------------------------------------------
 
+// ringSize returns the number of nodes in the ring of q.
+func ringSize(q *Queue) int {
+	if q.head == nil {
+		return 0
+	}
+	size := 1
+	for n := q.head.n; n != q.head; n = n.n {
+		size++
+	}
+	return size
+}
+
 // Checks the internal slices and its linkq.
 func checkLinks(t *testing.T, q *Queue, length, tailSliceSize int) {
 	t.Helper()
@@ -153,23 +547,165 @@ func checkLinks(t *testing.T, q *Queue, length, tailSliceSize int) {
 // i measured from the head of the queue.
 func assertInvariants(t *testing.T, q *Queue, val func(i int) interface{}) {
 	t.Helper()
-	fail := func(what string, got, want interface{}) {
-		t.Errorf("invariant fail: %s; got %v want %v", what, got, want)
-	}
 	if q == nil {
-		fail("non-nil queue", q, "non-nil")
+		t.Fatalf("invariant fail: non-nil queue; got %v want non-nil", q)
+	}
+	if err := q.checkInvariants(); err != nil {
+		t.Fatal(err)
 	}
-	if q.tail == nil {
-		// Zero value.
-		if q.tail != nil {
-			fail("nil tail when zero", q.tail, nil)
+	if val == nil || q.tail == nil {
+		return
+	}
+
+	// Check the values from head to tail.
+	i := 0
+	for n, start := q.head, q.hp; ; n, start = n.n, 0 {
+		end := len(n.v)
+		if n == q.tail {
+			end = q.tp
 		}
-		if q.len != 0 {
-			fail("zero length when zero", q.len, 0)
+		for j := start; j < end; j++ {
+			if n.v[j] != val(i) {
+				t.Errorf("invariant fail: value at index %d; got %v want %v", i, n.v[j], val(i))
+			}
+			i++
+		}
+		if n == q.tail {
+			break
 		}
-		return
 	}
 	if t.Failed() {
 		t.FailNow()
 	}
 }
+
+func TestQueueShouldPanicOnConcurrentUseWithDebugTag(t *testing.T) {
+	if !debug {
+		t.Skip("requires the queuedebug build tag")
+	}
+	var q Queue
+	q.Push(1)
+
+	// Simulate a Pop in progress in another goroutine.
+	q.debugEnter("Pop")
+	for name, op := range map[string]func(){
+		"Init":          func() { q.Init() },
+		"Reset":         func() { q.Reset() },
+		"Reserve":       func() { q.Reserve(1) },
+		"SetTrimPolicy": func() { q.SetTrimPolicy(nil) },
+		"Shrink":        func() { q.Shrink() },
+		"Front":         func() { q.Front() },
+		"Back":          func() { q.Back() },
+		"At":            func() { q.At(0) },
+		"Set":           func() { q.Set(0, 2) },
+		"Swap":          func() { q.Swap(0, 0) },
+		"Push":          func() { q.Push(2) },
+		"PushSlice":     func() { q.PushSlice([]interface{}{2}) },
+		"PushFront":     func() { q.PushFront(2) },
+		"Pop":           func() { q.Pop() },
+		"PopBack":       func() { q.PopBack() },
+		"PopN":          func() { q.PopN(1) },
+		"AppendTo":      func() { q.AppendTo(nil, 1) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s: Expected: panic; Got: none", name)
+				}
+			}()
+			op()
+		}()
+	}
+	q.debugExit()
+
+	// Once the other operation completes, the queue is usable again.
+	q.Push(2)
+	if v, ok := q.Pop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+}
+
+func TestQueueShouldAllowConcurrentReadsWithDebugTag(t *testing.T) {
+	if !debug {
+		t.Skip("requires the queuedebug build tag")
+	}
+	var q Queue
+	q.Push(1)
+
+	// Simulate a Front in progress in another goroutine.
+	q.debugEnterRead("Front")
+	if v, ok := q.Front(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	if v, ok := q.Back(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	if v := q.At(0); v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Push: Expected: panic; Got: none")
+			}
+		}()
+		q.Push(2)
+	}()
+	q.debugExitRead()
+
+	// Once the reads complete, the queue can be modified again.
+	q.Push(2)
+	if v, ok := q.Pop(); !ok || v.(int) != 1 {
+		t.Errorf("Expected: 1; Got: %v", v)
+	}
+}
+
+func TestQueueShouldPanicOnCorruptedQueueWithDebugTag(t *testing.T) {
+	if !debug {
+		t.Skip("requires the queuedebug build tag")
+	}
+	var q Queue
+	q.Push(1)
+	q.len++ // Simulate a corruption caused by an unsynchronized access.
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected: panic; Got: none")
+		}
+	}()
+	q.Push(2)
+}
+
+func TestNewWithOptionsShouldOnlyGrowTheFirstSlice(t *testing.T) {
+	tests := map[string]struct {
+		opts         []Option
+		internalSize int
+	}{
+		"default":                 {nil, maxInternalSliceSize},
+		"tiny":                    {[]Option{WithMaxInternalSliceSize(1)}, 1},
+		"small":                   {[]Option{WithMaxInternalSliceSize(7)}, 7},
+		"large first slice":       {[]Option{WithFirstSliceSize(32), WithMaxFirstSliceSize(128), WithMaxInternalSliceSize(16)}, 16},
+		"growing beyond internal": {[]Option{WithFirstSliceSize(2), WithSliceGrowthFactor(8), WithMaxInternalSliceSize(8)}, 8},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := NewWithOptions(test.opts...)
+			if err != nil {
+				t.Fatalf("Expected: nil error; Got: %v", err)
+			}
+			for i := 0; i < pushCount; i++ {
+				q.Push(i)
+			}
+			// The head is still the first slice, as no values were popped.
+			for n := q.head.n; n != q.head; n = n.n {
+				if len(n.v) != test.internalSize {
+					t.Fatalf("Expected: internal slices of size %d; Got: %d", test.internalSize, len(n.v))
+				}
+			}
+			if len(q.head.v) > test.internalSize && test.internalSize < maxFirstSliceSize {
+				t.Errorf("Expected: first slice up to size %d; Got: %d", test.internalSize, len(q.head.v))
+			}
+			assertInvariants(t, q, func(i int) interface{} { return i })
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// WorkerPool runs tasks on a number of worker goroutines, with the tasks
+// waiting for a free worker held in a BlockingQueue, so submitting a task
+// never blocks. Tasks are started in the order they were submitted.
+//
+// A task that panics doesn't bring down its worker: the panic is recovered and
+// reported to the pool panic callback.
+//
+// WorkerPool is safe for concurrent use.
+type WorkerPool struct {
+	// tasks holds the tasks waiting for a free worker.
+	tasks BlockingQueue
+
+	// onPanic is called with the value recovered from a panicking task.
+	// A nil onPanic means panics are recovered and ignored.
+	onPanic func(v interface{})
+
+	// ctx is cancelled when the pending tasks are abandoned, stopping all
+	// workers.
+	ctx context.Context
+
+	// abandon cancels ctx.
+	abandon context.CancelFunc
+
+	// wg tracks the running workers.
+	wg sync.WaitGroup
+
+	// mu protects stops and shutdown.
+	mu sync.Mutex
+
+	// stops holds one function per worker that, when called, stops that
+	// worker after its current task.
+	stops []context.CancelFunc
+
+	// shutdown indicates whether Shutdown was called.
+	shutdown bool
+}
+
+// NewWorkerPool returns a worker pool running workers worker goroutines.
+// onPanic, if not nil, is called from the worker goroutine with the value
+// recovered from every task that panics.
+// NewWorkerPool panics if workers is negative.
+func NewWorkerPool(workers int, onPanic func(v interface{})) *WorkerPool {
+	if workers < 0 {
+		panic("queue: negative worker count")
+	}
+	d := &WorkerPool{onPanic: onPanic}
+	d.ctx, d.abandon = context.WithCancel(context.Background())
+	d.Resize(workers)
+	return d
+}
+
+// Submit adds task t to the pool, to be run as soon as a worker is free.
+// If the pool is shut down, t is not added and ErrClosed is returned.
+// The complexity is O(1).
+func (d *WorkerPool) Submit(t func()) error {
+	return d.tasks.Push(t)
+}
+
+// Pending returns the number of submitted tasks that haven't been started yet.
+func (d *WorkerPool) Pending() int {
+	return d.tasks.Len()
+}
+
+// Workers returns the number of workers of pool d.
+// Workers stopped by Resize are not counted, even while they finish their
+// current task. Once Shutdown returns, Workers returns zero.
+func (d *WorkerPool) Workers() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.stops)
+}
+
+// Resize changes the number of workers of pool d to workers.
+// When shrinking, the stopped workers finish their current task before
+// exiting; the pending tasks are left for the remaining workers.
+// Resizing to zero pauses the pool until it is resized again.
+// Resize has no effect once the pool is shut down.
+// Resize panics if workers is negative.
+func (d *WorkerPool) Resize(workers int) {
+	if workers < 0 {
+		panic("queue: negative worker count")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.shutdown {
+		return
+	}
+	for len(d.stops) < workers {
+		ctx, stop := context.WithCancel(d.ctx)
+		d.stops = append(d.stops, stop)
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+	for len(d.stops) > workers {
+		last := len(d.stops) - 1
+		d.stops[last]()
+		d.stops[last] = nil // Avoid memory leaks
+		d.stops = d.stops[:last]
+	}
+}
+
+// Shutdown stops accepting new tasks and waits for the workers to run all
+// pending tasks and exit, in which case nil is returned. Shutdown only returns
+// nil once no task is pending.
+// If ctx is done first, the tasks not started yet are abandoned, the workers
+// are told to exit after their current task and ctx.Err() is returned right
+// away, without waiting for the running tasks to complete. Calling Shutdown
+// with a done ctx abandons the pending tasks right away.
+// A paused pool (resized to zero) never drains, so Shutdown only returns when
+// ctx is done.
+func (d *WorkerPool) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.shutdown = true
+	d.tasks.Close()
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		// No worker is left to run the pending tasks, if any, so the pool
+		// is only drained if none is pending.
+		if d.tasks.Len() == 0 {
+			close(done)
+		}
+	}()
+	select {
+	case <-done:
+		d.stop()
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.stop()
+	for {
+		if _, ok := d.tasks.TryPop(); !ok {
+			break
+		}
+	}
+	return ctx.Err()
+}
+
+// stop cancels the context of pool d and of all its workers, telling any
+// workers still running to exit after their current task.
+func (d *WorkerPool) stop() {
+	d.abandon()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, stop := range d.stops {
+		stop()
+		d.stops[i] = nil // Avoid memory leaks
+	}
+	d.stops = d.stops[:0]
+}
+
+// work runs pending tasks until ctx is done or the pool is shut down and
+// drained.
+func (d *WorkerPool) work(ctx context.Context) {
+	defer d.wg.Done()
+	for ctx.Err() == nil {
+		t, err := d.tasks.PopWait(ctx)
+		if err != nil {
+			return
+		}
+		d.run(t.(func()))
+	}
+}
+
+// run runs task t, recovering and reporting its panics.
+func (d *WorkerPool) run(t func()) {
+	defer func() {
+		if r := recover(); r != nil && d.onPanic != nil {
+			d.onPanic(r)
+		}
+	}()
+	t()
+}
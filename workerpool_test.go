@@ -0,0 +1,195 @@
+// Copyright (c) 2018 ef-ds
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package queue_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ef-ds/queue"
+)
+
+func TestWorkerPoolShouldRunAllSubmittedTasks(t *testing.T) {
+	p := queue.NewWorkerPool(4, nil)
+	var count atomic.Int64
+	for i := 0; i < pushCount; i++ {
+		if err := p.Submit(func() { count.Add(1) }); err != nil {
+			t.Fatalf("Expected: nil error; Got: %v", err)
+		}
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected: nil error; Got: %v", err)
+	}
+	if c := count.Load(); c != pushCount {
+		t.Errorf("Expected: %d; Got: %d", pushCount, c)
+	}
+	if err := p.Submit(func() {}); err != queue.ErrClosed {
+		t.Errorf("Expected: %v; Got: %v", queue.ErrClosed, err)
+	}
+}
+
+func TestWorkerPoolWithSingleWorkerShouldRunTasksInOrder(t *testing.T) {
+	p := queue.NewWorkerPool(1, nil)
+	var got []int
+	for i := 0; i < pushCount; i++ {
+		i := i
+		p.Submit(func() { got = append(got, i) })
+	}
+	p.Shutdown(context.Background())
+
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("Expected: %d; Got: %d", i, v)
+		}
+	}
+	if len(got) != pushCount {
+		t.Errorf("Expected: %d; Got: %d", pushCount, len(got))
+	}
+}
+
+func TestWorkerPoolShouldRecoverAndReportPanics(t *testing.T) {
+	var mu sync.Mutex
+	var recovered []interface{}
+	p := queue.NewWorkerPool(1, func(v interface{}) {
+		mu.Lock()
+		recovered = append(recovered, v)
+		mu.Unlock()
+	})
+	var count atomic.Int64
+	p.Submit(func() { panic("boom") })
+	p.Submit(func() { count.Add(1) })
+	p.Shutdown(context.Background())
+
+	if len(recovered) != 1 || recovered[0] != "boom" {
+		t.Errorf("Expected: [boom]; Got: %v", recovered)
+	}
+	if c := count.Load(); c != 1 {
+		t.Errorf("Expected: the worker to survive the panic; Got: %d tasks run", c)
+	}
+}
+
+func TestWorkerPoolShouldPauseAndResumeWhenResized(t *testing.T) {
+	p := queue.NewWorkerPool(2, nil)
+	p.Resize(0)
+	if w := p.Workers(); w != 0 {
+		t.Errorf("Expected: 0; Got: %d", w)
+	}
+	var count atomic.Int64
+	for i := 0; i < 10; i++ {
+		p.Submit(func() { count.Add(1) })
+	}
+	time.Sleep(10 * time.Millisecond)
+	if c := count.Load(); c != 0 {
+		t.Errorf("Expected: no tasks run while paused; Got: %d", c)
+	}
+	if l := p.Pending(); l != 10 {
+		t.Errorf("Expected: 10; Got: %d", l)
+	}
+
+	p.Resize(3)
+	if w := p.Workers(); w != 3 {
+		t.Errorf("Expected: 3; Got: %d", w)
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected: nil error; Got: %v", err)
+	}
+	if c := count.Load(); c != 10 {
+		t.Errorf("Expected: 10; Got: %d", c)
+	}
+
+	if w := p.Workers(); w != 0 {
+		t.Errorf("Expected: 0; Got: %d", w)
+	}
+
+	// Resize has no effect after shutdown.
+	p.Resize(1)
+	if w := p.Workers(); w != 0 {
+		t.Errorf("Expected: 0; Got: %d", w)
+	}
+}
+
+func TestWorkerPoolShutdownShouldWaitForContextWhenPaused(t *testing.T) {
+	for name, p := range map[string]*queue.WorkerPool{
+		"new":     queue.NewWorkerPool(0, nil),
+		"resized": queue.NewWorkerPool(1, nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			p.Resize(0)
+			time.Sleep(10 * time.Millisecond) // Let the stopped worker exit.
+			var count atomic.Int64
+			p.Submit(func() { count.Add(1) })
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+				t.Errorf("Expected: %v; Got: %v", context.DeadlineExceeded, err)
+			}
+			if c := count.Load(); c != 0 {
+				t.Errorf("Expected: no tasks run; Got: %d", c)
+			}
+			if l := p.Pending(); l != 0 {
+				t.Errorf("Expected: the pending task abandoned; Got: %d pending", l)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolShutdownShouldAbandonPendingTasksWhenContextIsDone(t *testing.T) {
+	p := queue.NewWorkerPool(1, nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-release
+	})
+	var count atomic.Int64
+	for i := 0; i < 10; i++ {
+		p.Submit(func() { count.Add(1) })
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected: %v; Got: %v", context.DeadlineExceeded, err)
+	}
+	if l := p.Pending(); l != 0 {
+		t.Errorf("Expected: 0; Got: %d", l)
+	}
+
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if c := count.Load(); c != 0 {
+		t.Errorf("Expected: abandoned tasks not to run; Got: %d", c)
+	}
+}
+
+func TestNewWorkerPoolWithNegativeWorkersShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected: panic; Got: none")
+		}
+	}()
+	queue.NewWorkerPool(-1, nil)
+}